@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/h4yfans/case-study/common"
+	"github.com/h4yfans/case-study/common/middleware"
+	"github.com/h4yfans/case-study/domain"
+)
+
+type AuthHandler struct {
+	usecase domain.AuthUsecase
+}
+
+func NewAuthHandler(usecase domain.AuthUsecase, r *mux.Router, auth *middleware.Authentication) {
+	handler := AuthHandler{usecase: usecase}
+
+	auth.Public(r.HandleFunc("/auth/login", handler.Login).Methods(http.MethodPost))
+	auth.Public(r.HandleFunc("/auth/refresh", handler.Refresh).Methods(http.MethodPost))
+	auth.Public(r.HandleFunc("/auth/logout", handler.Logout).Methods(http.MethodPost))
+	auth.Public(r.HandleFunc("/auth/password/forgot", handler.ForgotPassword).Methods(http.MethodPost))
+	auth.Public(r.HandleFunc("/auth/password/reset", handler.ResetPassword).Methods(http.MethodPost))
+	auth.Public(r.HandleFunc("/auth/login/2fa", handler.LoginTwoFactor).Methods(http.MethodPost))
+	r.HandleFunc("/auth/2fa/enroll", handler.EnrollTOTP).Methods(http.MethodPost)
+	r.HandleFunc("/auth/2fa/confirm", handler.ConfirmTOTP).Methods(http.MethodPost)
+}
+
+func (a *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var credentials domain.LoginRequest
+	err := json.NewDecoder(r.Body).Decode(&credentials)
+	if err != nil {
+		common.RespondWithJSON(w, http.StatusBadRequest, common.ResponseError{Error: common.BadRequest.Error()})
+		return
+	}
+
+	credentials.IP = common.ClientIP(r)
+	tokenData, err := a.usecase.Login(r.Context(), &credentials)
+	if err != nil {
+		common.RespondWithJSON(w, common.GetStatusCode(err), common.ResponseError{Error: err.Error()})
+		return
+	}
+
+	common.RespondWithJSON(w, http.StatusOK, tokenData)
+	return
+}
+
+func (a *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var body domain.RefreshRequest
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		common.RespondWithJSON(w, http.StatusBadRequest, common.ResponseError{Error: common.BadRequest.Error()})
+		return
+	}
+
+	tokenData, err := a.usecase.Refresh(r.Context(), body.RefreshToken)
+	if err != nil {
+		common.RespondWithJSON(w, common.GetStatusCode(err), common.ResponseError{Error: err.Error()})
+		return
+	}
+
+	common.RespondWithJSON(w, http.StatusOK, tokenData)
+	return
+}
+
+func (a *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	var body domain.RefreshRequest
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		common.RespondWithJSON(w, http.StatusBadRequest, common.ResponseError{Error: common.BadRequest.Error()})
+		return
+	}
+
+	err = a.usecase.Logout(r.Context(), body.RefreshToken)
+	if err != nil {
+		common.RespondWithJSON(w, common.GetStatusCode(err), common.ResponseError{Error: err.Error()})
+		return
+	}
+
+	common.RespondWithJSON(w, http.StatusNoContent, nil)
+	return
+}
+
+func (a *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var body domain.ForgotPasswordRequest
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		common.RespondWithJSON(w, http.StatusBadRequest, common.ResponseError{Error: common.BadRequest.Error()})
+		return
+	}
+
+	err = a.usecase.ForgotPassword(r.Context(), body.Email)
+	if err != nil {
+		common.RespondWithJSON(w, common.GetStatusCode(err), common.ResponseError{Error: err.Error()})
+		return
+	}
+
+	common.RespondWithJSON(w, http.StatusAccepted, nil)
+	return
+}
+
+func (a *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var body domain.PasswordResetRequest
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		common.RespondWithJSON(w, http.StatusBadRequest, common.ResponseError{Error: common.BadRequest.Error()})
+		return
+	}
+
+	err = a.usecase.ResetPassword(r.Context(), &body)
+	if err != nil {
+		common.RespondWithJSON(w, common.GetStatusCode(err), common.NewResponseError(err))
+		return
+	}
+
+	common.RespondWithJSON(w, http.StatusNoContent, nil)
+	return
+}
+
+func (a *AuthHandler) LoginTwoFactor(w http.ResponseWriter, r *http.Request) {
+	var body domain.TwoFactorLoginRequest
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		common.RespondWithJSON(w, http.StatusBadRequest, common.ResponseError{Error: common.BadRequest.Error()})
+		return
+	}
+
+	body.IP = common.ClientIP(r)
+	tokenData, err := a.usecase.LoginTwoFactor(r.Context(), &body)
+	if err != nil {
+		common.RespondWithJSON(w, common.GetStatusCode(err), common.ResponseError{Error: err.Error()})
+		return
+	}
+
+	common.RespondWithJSON(w, http.StatusOK, tokenData)
+	return
+}
+
+func (a *AuthHandler) EnrollTOTP(w http.ResponseWriter, r *http.Request) {
+	principal, _ := middleware.PrincipalFromContext(r.Context())
+	enrollment, err := a.usecase.EnrollTOTP(r.Context(), principal)
+	if err != nil {
+		common.RespondWithJSON(w, common.GetStatusCode(err), common.ResponseError{Error: err.Error()})
+		return
+	}
+
+	common.RespondWithJSON(w, http.StatusOK, enrollment)
+	return
+}
+
+func (a *AuthHandler) ConfirmTOTP(w http.ResponseWriter, r *http.Request) {
+	var body domain.TOTPConfirmRequest
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		common.RespondWithJSON(w, http.StatusBadRequest, common.ResponseError{Error: common.BadRequest.Error()})
+		return
+	}
+
+	principal, _ := middleware.PrincipalFromContext(r.Context())
+	recoveryCodes, err := a.usecase.ConfirmTOTP(r.Context(), principal, body.Code)
+	if err != nil {
+		common.RespondWithJSON(w, common.GetStatusCode(err), common.ResponseError{Error: err.Error()})
+		return
+	}
+
+	common.RespondWithJSON(w, http.StatusOK, recoveryCodes)
+	return
+}
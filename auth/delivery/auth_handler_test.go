@@ -0,0 +1,371 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/h4yfans/case-study/common"
+	"github.com/h4yfans/case-study/common/middleware"
+	"github.com/h4yfans/case-study/domain"
+	mocks "github.com/h4yfans/case-study/mocks/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogin(t *testing.T) {
+	t.Run("should return 200", func(t *testing.T) {
+		// Mock body
+		credentials := domain.LoginRequest{
+			Email:    "kaan@test.com",
+			Password: "123123",
+		}
+		r, err := json.Marshal(&credentials)
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(string(r)))
+		assert.NoError(t, err)
+
+		// Mock response
+		tokenResponse := &domain.TokenResponse{
+			AccessToken: "token",
+			TokenType:   "Bearer",
+			ExpiresIn:   900,
+		}
+
+		mockUCase := new(mocks.AuthUsecase)
+		mockUCase.On("Login", req.Context(), &credentials).Return(tokenResponse, nil)
+
+		rec := httptest.NewRecorder()
+		handler := AuthHandler{usecase: mockUCase}
+
+		handler.Login(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockUCase.AssertExpectations(t)
+	})
+
+	t.Run("should return 400", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/auth/login", strings.NewReader("{"))
+		assert.NoError(t, err)
+
+		mockUCase := new(mocks.AuthUsecase)
+
+		rec := httptest.NewRecorder()
+		handler := AuthHandler{usecase: mockUCase}
+
+		handler.Login(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockUCase.AssertExpectations(t)
+	})
+
+	t.Run("should return 401", func(t *testing.T) {
+		// Mock body
+		credentials := domain.LoginRequest{
+			Email:    "kaan@test.com",
+			Password: "wrong",
+		}
+		r, err := json.Marshal(&credentials)
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(string(r)))
+		assert.NoError(t, err)
+
+		mockUCase := new(mocks.AuthUsecase)
+		mockUCase.On("Login", req.Context(), &credentials).Return(nil, common.InvalidCredentials)
+
+		rec := httptest.NewRecorder()
+		handler := AuthHandler{usecase: mockUCase}
+
+		handler.Login(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		mockUCase.AssertExpectations(t)
+	})
+
+	t.Run("should return 423", func(t *testing.T) {
+		credentials := domain.LoginRequest{
+			Email:    "kaan@test.com",
+			Password: "123123",
+		}
+		r, err := json.Marshal(&credentials)
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(string(r)))
+
+		// The handler fills in the source address of the request
+		credentials.IP = "192.0.2.1"
+
+		mockUCase := new(mocks.AuthUsecase)
+		mockUCase.On("Login", req.Context(), &credentials).Return(nil, common.AccountLocked)
+
+		rec := httptest.NewRecorder()
+		handler := AuthHandler{usecase: mockUCase}
+
+		handler.Login(rec, req)
+		assert.Equal(t, http.StatusLocked, rec.Code)
+		mockUCase.AssertExpectations(t)
+	})
+}
+
+func TestRefresh(t *testing.T) {
+	t.Run("should return 200", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/auth/refresh", strings.NewReader(`{"refresh_token":"token"}`))
+		assert.NoError(t, err)
+
+		tokenResponse := &domain.TokenResponse{
+			AccessToken:  "access",
+			RefreshToken: "refresh",
+			TokenType:    "Bearer",
+			ExpiresIn:    900,
+		}
+
+		mockUCase := new(mocks.AuthUsecase)
+		mockUCase.On("Refresh", req.Context(), "token").Return(tokenResponse, nil)
+
+		rec := httptest.NewRecorder()
+		handler := AuthHandler{usecase: mockUCase}
+
+		handler.Refresh(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockUCase.AssertExpectations(t)
+	})
+
+	t.Run("should return 401", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/auth/refresh", strings.NewReader(`{"refresh_token":"reused"}`))
+		assert.NoError(t, err)
+
+		mockUCase := new(mocks.AuthUsecase)
+		mockUCase.On("Refresh", req.Context(), "reused").Return(nil, common.InvalidToken)
+
+		rec := httptest.NewRecorder()
+		handler := AuthHandler{usecase: mockUCase}
+
+		handler.Refresh(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		mockUCase.AssertExpectations(t)
+	})
+}
+
+func TestLogout(t *testing.T) {
+	t.Run("should return 204", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/auth/logout", strings.NewReader(`{"refresh_token":"token"}`))
+		assert.NoError(t, err)
+
+		mockUCase := new(mocks.AuthUsecase)
+		mockUCase.On("Logout", req.Context(), "token").Return(nil)
+
+		rec := httptest.NewRecorder()
+		handler := AuthHandler{usecase: mockUCase}
+
+		handler.Logout(rec, req)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		mockUCase.AssertExpectations(t)
+	})
+
+	t.Run("should return 400", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/auth/logout", strings.NewReader(`{}`))
+		assert.NoError(t, err)
+
+		mockUCase := new(mocks.AuthUsecase)
+		mockUCase.On("Logout", req.Context(), "").Return(common.BadRequest)
+
+		rec := httptest.NewRecorder()
+		handler := AuthHandler{usecase: mockUCase}
+
+		handler.Logout(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockUCase.AssertExpectations(t)
+	})
+}
+
+func TestForgotPassword(t *testing.T) {
+	t.Run("should return 202", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/auth/password/forgot", strings.NewReader(`{"email":"kaan@test.com"}`))
+		assert.NoError(t, err)
+
+		mockUCase := new(mocks.AuthUsecase)
+		mockUCase.On("ForgotPassword", req.Context(), "kaan@test.com").Return(nil)
+
+		rec := httptest.NewRecorder()
+		handler := AuthHandler{usecase: mockUCase}
+
+		handler.ForgotPassword(rec, req)
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		mockUCase.AssertExpectations(t)
+	})
+}
+
+func TestResetPassword(t *testing.T) {
+	t.Run("should return 204", func(t *testing.T) {
+		body := domain.PasswordResetRequest{Token: "token", Password: "new-password"}
+		r, err := json.Marshal(&body)
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, "/auth/password/reset", strings.NewReader(string(r)))
+		assert.NoError(t, err)
+
+		mockUCase := new(mocks.AuthUsecase)
+		mockUCase.On("ResetPassword", req.Context(), &body).Return(nil)
+
+		rec := httptest.NewRecorder()
+		handler := AuthHandler{usecase: mockUCase}
+
+		handler.ResetPassword(rec, req)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		mockUCase.AssertExpectations(t)
+	})
+
+	t.Run("should return 401", func(t *testing.T) {
+		body := domain.PasswordResetRequest{Token: "expired", Password: "new-password"}
+		r, err := json.Marshal(&body)
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, "/auth/password/reset", strings.NewReader(string(r)))
+		assert.NoError(t, err)
+
+		mockUCase := new(mocks.AuthUsecase)
+		mockUCase.On("ResetPassword", req.Context(), &body).Return(common.InvalidToken)
+
+		rec := httptest.NewRecorder()
+		handler := AuthHandler{usecase: mockUCase}
+
+		handler.ResetPassword(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		mockUCase.AssertExpectations(t)
+	})
+
+	t.Run("should return 400 with the broken rules", func(t *testing.T) {
+		body := domain.PasswordResetRequest{Token: "token", Password: "short"}
+		r, err := json.Marshal(&body)
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, "/auth/password/reset", strings.NewReader(string(r)))
+		assert.NoError(t, err)
+
+		violations := []common.Violation{{Field: "password", Rule: "min_length", Message: "Password must be at least 8 characters"}}
+
+		mockUCase := new(mocks.AuthUsecase)
+		mockUCase.On("ResetPassword", req.Context(), &body).Return(&common.ValidationError{Violations: violations})
+
+		rec := httptest.NewRecorder()
+		handler := AuthHandler{usecase: mockUCase}
+
+		handler.ResetPassword(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		var response common.ResponseError
+		err = json.NewDecoder(rec.Body).Decode(&response)
+		assert.NoError(t, err)
+		assert.Equal(t, violations, response.Violations)
+		mockUCase.AssertExpectations(t)
+	})
+}
+
+func TestLoginTwoFactor(t *testing.T) {
+	t.Run("should return 200", func(t *testing.T) {
+		body := domain.TwoFactorLoginRequest{ChallengeToken: "challenge", Code: "123456"}
+		r, err := json.Marshal(&body)
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, "/auth/login/2fa", strings.NewReader(string(r)))
+		assert.NoError(t, err)
+
+		tokenResponse := &domain.TokenResponse{
+			AccessToken:  "access",
+			RefreshToken: "refresh",
+			TokenType:    "Bearer",
+			ExpiresIn:    900,
+		}
+
+		mockUCase := new(mocks.AuthUsecase)
+		mockUCase.On("LoginTwoFactor", req.Context(), &body).Return(tokenResponse, nil)
+
+		rec := httptest.NewRecorder()
+		handler := AuthHandler{usecase: mockUCase}
+
+		handler.LoginTwoFactor(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockUCase.AssertExpectations(t)
+	})
+
+	t.Run("should return 401", func(t *testing.T) {
+		body := domain.TwoFactorLoginRequest{ChallengeToken: "challenge", Code: "000000"}
+		r, err := json.Marshal(&body)
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, "/auth/login/2fa", strings.NewReader(string(r)))
+		assert.NoError(t, err)
+
+		mockUCase := new(mocks.AuthUsecase)
+		mockUCase.On("LoginTwoFactor", req.Context(), &body).Return(nil, common.InvalidTwoFactorCode)
+
+		rec := httptest.NewRecorder()
+		handler := AuthHandler{usecase: mockUCase}
+
+		handler.LoginTwoFactor(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		mockUCase.AssertExpectations(t)
+	})
+}
+
+func TestEnrollTOTP(t *testing.T) {
+	t.Run("should return 200", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/auth/2fa/enroll", nil)
+		assert.NoError(t, err)
+
+		principal := &domain.Principal{UserID: 1, Role: domain.RoleUser}
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
+
+		enrollment := &domain.TOTPEnrollmentResponse{Secret: "SECRET", URI: "otpauth://totp/test:kaan@test.com?secret=SECRET"}
+
+		mockUCase := new(mocks.AuthUsecase)
+		mockUCase.On("EnrollTOTP", req.Context(), principal).Return(enrollment, nil)
+
+		rec := httptest.NewRecorder()
+		handler := AuthHandler{usecase: mockUCase}
+
+		handler.EnrollTOTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockUCase.AssertExpectations(t)
+	})
+
+	t.Run("should return 403", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/auth/2fa/enroll", nil)
+		assert.NoError(t, err)
+
+		principal := &domain.Principal{UserID: 1, Role: domain.RoleUser}
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
+
+		mockUCase := new(mocks.AuthUsecase)
+		mockUCase.On("EnrollTOTP", req.Context(), principal).Return(nil, common.TwoFactorEnabled)
+
+		rec := httptest.NewRecorder()
+		handler := AuthHandler{usecase: mockUCase}
+
+		handler.EnrollTOTP(rec, req)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		mockUCase.AssertExpectations(t)
+	})
+}
+
+func TestConfirmTOTP(t *testing.T) {
+	t.Run("should return 200", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/auth/2fa/confirm", strings.NewReader(`{"code":"123456"}`))
+		assert.NoError(t, err)
+
+		principal := &domain.Principal{UserID: 1, Role: domain.RoleUser}
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
+
+		recoveryCodes := &domain.RecoveryCodesResponse{RecoveryCodes: []string{"abcd-efgh"}}
+
+		mockUCase := new(mocks.AuthUsecase)
+		mockUCase.On("ConfirmTOTP", req.Context(), principal, "123456").Return(recoveryCodes, nil)
+
+		rec := httptest.NewRecorder()
+		handler := AuthHandler{usecase: mockUCase}
+
+		handler.ConfirmTOTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockUCase.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"github.com/h4yfans/case-study/common"
+	"github.com/h4yfans/case-study/common/db"
+)
+
+// authError maps a database error to the error reported to the client. Every
+// auth table that has a foreign key references users, so a violation means
+// the user is gone.
+func authError(err error) error {
+	switch db.Classify(err) {
+	case db.ClassForeignKeyViolation:
+		return common.UserNotExist
+	case db.ClassNotNullViolation, db.ClassCheckViolation:
+		return common.BadRequest
+	case db.ClassConflict:
+		return common.Conflict
+	case db.ClassTimeout:
+		return common.Timeout
+	}
+	return common.ServerError
+}
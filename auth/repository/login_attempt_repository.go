@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/h4yfans/case-study/domain"
+	"github.com/h4yfans/case-study/models"
+	"github.com/volatiletech/null/v8"
+)
+
+// recordFailureQuery counts the failure atomically so concurrent guesses
+// cannot slip past the threshold. Failures older than the window are
+// forgotten.
+const recordFailureQuery = `
+INSERT INTO login_attempts (key, failures, updated_at)
+VALUES ($1, 1, now())
+ON CONFLICT (key) DO UPDATE SET failures   = CASE
+                                                 WHEN login_attempts.updated_at < now() - $2 * interval '1 second'
+                                                     THEN 1
+                                                 ELSE login_attempts.failures + 1 END,
+                                updated_at = now()
+RETURNING failures`
+
+type LoginAttemptRepository struct {
+	db *sql.DB
+}
+
+func NewLoginAttemptRepository(db *sql.DB) domain.LoginAttemptRepository {
+	return &LoginAttemptRepository{
+		db: db,
+	}
+}
+
+// Get returns the attempts recorded for key, or an empty record if there are
+// none.
+func (r *LoginAttemptRepository) Get(ctx context.Context, key string) (*models.LoginAttempt, error) {
+	attempt, err := models.FindLoginAttempt(ctx, r.db, key)
+	if err == sql.ErrNoRows {
+		return &models.LoginAttempt{Key: key}, nil
+	}
+	if err != nil {
+		return nil, authError(err)
+	}
+
+	return attempt, nil
+}
+
+func (r *LoginAttemptRepository) RecordFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	var failures int
+	err := r.db.QueryRowContext(ctx, recordFailureQuery, key, int(window.Seconds())).Scan(&failures)
+	if err != nil {
+		return 0, authError(err)
+	}
+
+	return failures, nil
+}
+
+func (r *LoginAttemptRepository) Block(ctx context.Context, key string, until time.Time, locked bool) error {
+	_, err := models.LoginAttempts(models.LoginAttemptWhere.Key.EQ(key)).UpdateAll(ctx, r.db, models.M{
+		models.LoginAttemptColumns.BlockedUntil: null.TimeFrom(until),
+		models.LoginAttemptColumns.Locked:       locked,
+	})
+	if err != nil {
+		return authError(err)
+	}
+
+	return nil
+}
+
+func (r *LoginAttemptRepository) Reset(ctx context.Context, key string) error {
+	_, err := models.LoginAttempts(models.LoginAttemptWhere.Key.EQ(key)).DeleteAll(ctx, r.db)
+	if err != nil {
+		return authError(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/h4yfans/case-study/common"
+	"github.com/h4yfans/case-study/domain"
+	"github.com/h4yfans/case-study/models"
+	"github.com/volatiletech/sqlboiler/v4/boil"
+)
+
+type RecoveryCodeRepository struct {
+	db *sql.DB
+}
+
+func NewRecoveryCodeRepository(db *sql.DB) domain.RecoveryCodeRepository {
+	return &RecoveryCodeRepository{
+		db: db,
+	}
+}
+
+// Replace drops the user's previous recovery codes and stores the new set in
+// a single transaction.
+func (r *RecoveryCodeRepository) Replace(ctx context.Context, userID int, hashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return authError(err)
+	}
+	defer tx.Rollback()
+
+	_, err = models.RecoveryCodes(models.RecoveryCodeWhere.UserID.EQ(userID)).DeleteAll(ctx, tx)
+	if err != nil {
+		return authError(err)
+	}
+
+	for _, hash := range hashes {
+		code := models.RecoveryCode{UserID: userID, CodeHash: hash}
+		err = code.Insert(ctx, tx, boil.Infer())
+		if err != nil {
+			return authError(err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return authError(err)
+	}
+
+	return nil
+}
+
+// Use consumes the recovery code, reporting common.InvalidTwoFactorCode if it
+// does not exist or was already used.
+func (r *RecoveryCodeRepository) Use(ctx context.Context, userID int, hash string) error {
+	effected, err := models.RecoveryCodes(
+		models.RecoveryCodeWhere.UserID.EQ(userID),
+		models.RecoveryCodeWhere.CodeHash.EQ(hash),
+		models.RecoveryCodeWhere.UsedAt.IsNull(),
+	).UpdateAll(ctx, r.db, models.M{models.RecoveryCodeColumns.UsedAt: time.Now()})
+	if err != nil {
+		return authError(err)
+	}
+
+	if effected == 0 {
+		return common.InvalidTwoFactorCode
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/h4yfans/case-study/common"
+	"github.com/h4yfans/case-study/domain"
+	"github.com/h4yfans/case-study/models"
+	"github.com/volatiletech/sqlboiler/v4/boil"
+)
+
+type RefreshTokenRepository struct {
+	db *sql.DB
+}
+
+func NewRefreshTokenRepository(db *sql.DB) domain.RefreshTokenRepository {
+	return &RefreshTokenRepository{
+		db: db,
+	}
+}
+
+func (r *RefreshTokenRepository) Create(ctx context.Context, token *models.RefreshToken) (*models.RefreshToken, error) {
+	err := token.Insert(ctx, r.db, boil.Infer())
+	if err != nil {
+		return nil, authError(err)
+	}
+
+	return token, nil
+}
+
+func (r *RefreshTokenRepository) GetByHash(ctx context.Context, hash string) (*models.RefreshToken, error) {
+	token, err := models.RefreshTokens(models.RefreshTokenWhere.TokenHash.EQ(hash)).One(ctx, r.db)
+	if err == sql.ErrNoRows {
+		return nil, common.InvalidToken
+	}
+	if err != nil {
+		return nil, authError(err)
+	}
+
+	return token, nil
+}
+
+// MarkUsed consumes the token. Only one caller can win the update, so a
+// concurrent replay of the same token reports common.InvalidToken.
+func (r *RefreshTokenRepository) MarkUsed(ctx context.Context, id int) error {
+	effected, err := models.RefreshTokens(
+		models.RefreshTokenWhere.ID.EQ(id),
+		models.RefreshTokenWhere.UsedAt.IsNull(),
+		models.RefreshTokenWhere.RevokedAt.IsNull(),
+	).UpdateAll(ctx, r.db, models.M{models.RefreshTokenColumns.UsedAt: time.Now()})
+	if err != nil {
+		return authError(err)
+	}
+
+	if effected == 0 {
+		return common.InvalidToken
+	}
+
+	return nil
+}
+
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	_, err := models.RefreshTokens(
+		models.RefreshTokenWhere.FamilyID.EQ(familyID),
+		models.RefreshTokenWhere.RevokedAt.IsNull(),
+	).UpdateAll(ctx, r.db, models.M{models.RefreshTokenColumns.RevokedAt: time.Now()})
+	if err != nil {
+		return authError(err)
+	}
+
+	return nil
+}
+
+func (r *RefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID int) error {
+	_, err := models.RefreshTokens(
+		models.RefreshTokenWhere.UserID.EQ(userID),
+		models.RefreshTokenWhere.RevokedAt.IsNull(),
+	).UpdateAll(ctx, r.db, models.M{models.RefreshTokenColumns.RevokedAt: time.Now()})
+	if err != nil {
+		return authError(err)
+	}
+
+	return nil
+}
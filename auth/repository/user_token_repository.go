@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/h4yfans/case-study/common"
+	"github.com/h4yfans/case-study/domain"
+	"github.com/h4yfans/case-study/models"
+	"github.com/volatiletech/sqlboiler/v4/boil"
+)
+
+type UserTokenRepository struct {
+	db *sql.DB
+}
+
+func NewUserTokenRepository(db *sql.DB) domain.UserTokenRepository {
+	return &UserTokenRepository{
+		db: db,
+	}
+}
+
+func (r *UserTokenRepository) Create(ctx context.Context, token *models.UserToken) (*models.UserToken, error) {
+	err := token.Insert(ctx, r.db, boil.Infer())
+	if err != nil {
+		return nil, authError(err)
+	}
+
+	return token, nil
+}
+
+func (r *UserTokenRepository) GetByHash(ctx context.Context, purpose string, hash string) (*models.UserToken, error) {
+	token, err := models.UserTokens(
+		models.UserTokenWhere.Purpose.EQ(purpose),
+		models.UserTokenWhere.TokenHash.EQ(hash),
+	).One(ctx, r.db)
+	if err == sql.ErrNoRows {
+		return nil, common.InvalidToken
+	}
+	if err != nil {
+		return nil, authError(err)
+	}
+
+	return token, nil
+}
+
+// MarkUsed consumes the token, reporting common.InvalidToken if it was
+// already used.
+func (r *UserTokenRepository) MarkUsed(ctx context.Context, id int) error {
+	effected, err := models.UserTokens(
+		models.UserTokenWhere.ID.EQ(id),
+		models.UserTokenWhere.UsedAt.IsNull(),
+	).UpdateAll(ctx, r.db, models.M{models.UserTokenColumns.UsedAt: time.Now()})
+	if err != nil {
+		return authError(err)
+	}
+
+	if effected == 0 {
+		return common.InvalidToken
+	}
+
+	return nil
+}
+
+func (r *UserTokenRepository) InvalidateAll(ctx context.Context, userID int, purpose string) error {
+	_, err := models.UserTokens(
+		models.UserTokenWhere.UserID.EQ(userID),
+		models.UserTokenWhere.Purpose.EQ(purpose),
+		models.UserTokenWhere.UsedAt.IsNull(),
+	).UpdateAll(ctx, r.db, models.M{models.UserTokenColumns.UsedAt: time.Now()})
+	if err != nil {
+		return authError(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,622 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/h4yfans/case-study/common"
+	"github.com/h4yfans/case-study/common/emailaddr"
+	"github.com/h4yfans/case-study/common/lockout"
+	"github.com/h4yfans/case-study/common/logging"
+	"github.com/h4yfans/case-study/common/password"
+	"github.com/h4yfans/case-study/common/token"
+	"github.com/h4yfans/case-study/common/totp"
+	"github.com/h4yfans/case-study/domain"
+	"github.com/h4yfans/case-study/models"
+	"go.uber.org/zap"
+)
+
+const TokenType = "Bearer"
+
+type AuthUsecase struct {
+	userRepo      domain.UserRepository
+	refreshRepo   domain.RefreshTokenRepository
+	roleRepo      domain.RoleRepository
+	userTokenRepo domain.UserTokenRepository
+	recoveryRepo  domain.RecoveryCodeRepository
+	attemptRepo   domain.LoginAttemptRepository
+	hasher        domain.PasswordHasher
+	passwords     *password.Policy
+	screener      domain.PasswordScreener
+	notifier      domain.Notifier
+	tokens        *token.Manager
+	totp          *totp.Manager
+	lockout       *lockout.Policy
+	emails        *emailaddr.Normalizer
+
+	requireVerifiedEmail bool
+}
+
+func NewAuthUsecase(
+	userRepo domain.UserRepository,
+	refreshRepo domain.RefreshTokenRepository,
+	roleRepo domain.RoleRepository,
+	userTokenRepo domain.UserTokenRepository,
+	recoveryRepo domain.RecoveryCodeRepository,
+	attemptRepo domain.LoginAttemptRepository,
+	hasher domain.PasswordHasher,
+	passwords *password.Policy,
+	screener domain.PasswordScreener,
+	notifier domain.Notifier,
+	tokens *token.Manager,
+	totp *totp.Manager,
+	lockout *lockout.Policy,
+	emails *emailaddr.Normalizer,
+	requireVerifiedEmail bool,
+) *AuthUsecase {
+	return &AuthUsecase{
+		userRepo:      userRepo,
+		refreshRepo:   refreshRepo,
+		roleRepo:      roleRepo,
+		userTokenRepo: userTokenRepo,
+		recoveryRepo:  recoveryRepo,
+		attemptRepo:   attemptRepo,
+		hasher:        hasher,
+		passwords:     passwords,
+		screener:      screener,
+		notifier:      notifier,
+		tokens:        tokens,
+		totp:          totp,
+		lockout:       lockout,
+		emails:        emails,
+
+		requireVerifiedEmail: requireVerifiedEmail,
+	}
+}
+
+func (a *AuthUsecase) Login(ctx context.Context, credentials *domain.LoginRequest) (*domain.TokenResponse, error) {
+	if strings.TrimSpace(credentials.Email) == "" || credentials.Password == "" {
+		return nil, common.BadRequest
+	}
+
+	// An address that does not normalize belongs to nobody, but is still
+	// looked up and counted like any other so it fails the same way.
+	if email, err := a.emails.Normalize(credentials.Email); err == nil {
+		credentials.Email = email
+	}
+
+	err := a.checkAttempts(ctx, credentials.Email, credentials.IP)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := a.userRepo.GetByEmail(ctx, credentials.Email)
+	if err == common.UserNotExist {
+		// Hashing costs as much as a verification, so a missing user takes as
+		// long to reject as a wrong password.
+		_, _ = a.hasher.HashPassword(credentials.Password)
+		return nil, a.recordFailure(ctx, credentials.Email, credentials.IP, common.InvalidCredentials)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ok, rehash, err := a.hasher.VerifyPassword(user.Password, credentials.Password)
+	if err != nil {
+		logging.FromContext(ctx).Error("Password hash could not be verified", zap.Error(err), zap.Int("user_id", user.ID))
+	}
+	if !ok {
+		return nil, a.recordFailure(ctx, credentials.Email, credentials.IP, common.InvalidCredentials)
+	}
+
+	if rehash {
+		a.rehash(ctx, user.ID, credentials.Password)
+	}
+
+	if a.requireVerifiedEmail && !user.EmailVerifiedAt.Valid {
+		return nil, common.EmailNotVerified
+	}
+
+	if user.TotpEnabledAt.Valid {
+		return a.challenge(ctx, user.ID)
+	}
+
+	err = a.attemptRepo.Reset(ctx, lockout.EmailKey(user.Email))
+	if err != nil {
+		return nil, err
+	}
+
+	familyID, err := token.NewID()
+	if err != nil {
+		return nil, common.ServerError
+	}
+
+	return a.issue(ctx, user.ID, familyID)
+}
+
+// LoginTwoFactor completes a login that was answered with a challenge. The
+// code is either a TOTP code or one of the user's recovery codes.
+func (a *AuthUsecase) LoginTwoFactor(ctx context.Context, request *domain.TwoFactorLoginRequest) (*domain.TokenResponse, error) {
+	if request.ChallengeToken == "" || strings.TrimSpace(request.Code) == "" {
+		return nil, common.BadRequest
+	}
+
+	stored, err := a.userTokenRepo.GetByHash(ctx, domain.TokenPurposeTwoFactor, token.HashOpaque(request.ChallengeToken))
+	if err != nil {
+		return nil, err
+	}
+
+	if stored.UsedAt.Valid || time.Now().After(stored.ExpiresAt) {
+		return nil, common.InvalidToken
+	}
+
+	user, err := a.userRepo.GetByID(ctx, stored.UserID)
+	if err == common.UserNotExist {
+		return nil, common.InvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.checkAttempts(ctx, user.Email, request.IP)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.verifySecondFactor(ctx, user, request.Code)
+	if err == common.InvalidTwoFactorCode {
+		return nil, a.recordFailure(ctx, user.Email, request.IP, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.userTokenRepo.MarkUsed(ctx, stored.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.attemptRepo.Reset(ctx, lockout.EmailKey(user.Email))
+	if err != nil {
+		return nil, err
+	}
+
+	familyID, err := token.NewID()
+	if err != nil {
+		return nil, common.ServerError
+	}
+
+	return a.issue(ctx, user.ID, familyID)
+}
+
+// EnrollTOTP generates a new secret for the principal. It is not enforced
+// until ConfirmTOTP proves the authenticator app was set up.
+func (a *AuthUsecase) EnrollTOTP(ctx context.Context, principal *domain.Principal) (*domain.TOTPEnrollmentResponse, error) {
+	if principal == nil {
+		return nil, common.Unauthorized
+	}
+
+	user, err := a.userRepo.GetByID(ctx, principal.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TotpEnabledAt.Valid {
+		return nil, common.TwoFactorEnabled
+	}
+
+	secret, err := a.totp.GenerateSecret()
+	if err != nil {
+		return nil, common.ServerError
+	}
+
+	sealed, err := a.totp.Seal(secret)
+	if err != nil {
+		return nil, common.ServerError
+	}
+
+	err = a.userRepo.SetTOTPSecret(ctx, user.ID, sealed)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.TOTPEnrollmentResponse{
+		Secret: secret,
+		URI:    a.totp.URI(user.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTP enables the second factor once the user submits a valid code
+// and hands out the recovery codes. They are only ever shown here.
+func (a *AuthUsecase) ConfirmTOTP(ctx context.Context, principal *domain.Principal, code string) (*domain.RecoveryCodesResponse, error) {
+	if principal == nil {
+		return nil, common.Unauthorized
+	}
+
+	if strings.TrimSpace(code) == "" {
+		return nil, common.BadRequest
+	}
+
+	user, err := a.userRepo.GetByID(ctx, principal.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TotpEnabledAt.Valid {
+		return nil, common.TwoFactorEnabled
+	}
+
+	if !user.TotpSecret.Valid {
+		return nil, common.BadRequest
+	}
+
+	err = a.verifyTOTP(ctx, user, code)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := totp.NewRecoveryCodes()
+	if err != nil {
+		return nil, common.ServerError
+	}
+
+	hashes := make([]string, len(codes))
+	for i, recoveryCode := range codes {
+		hashes[i] = token.HashOpaque(totp.NormalizeRecoveryCode(recoveryCode))
+	}
+
+	err = a.recoveryRepo.Replace(ctx, user.ID, hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.userRepo.EnableTOTP(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// Refresh rotates the refresh token. Presenting a token that was already
+// rotated or revoked is treated as theft and revokes its whole family.
+func (a *AuthUsecase) Refresh(ctx context.Context, refreshToken string) (*domain.TokenResponse, error) {
+	if refreshToken == "" {
+		return nil, common.BadRequest
+	}
+
+	stored, err := a.refreshRepo.GetByHash(ctx, token.HashOpaque(refreshToken))
+	if err != nil {
+		return nil, err
+	}
+
+	if stored.UsedAt.Valid || stored.RevokedAt.Valid {
+		return nil, a.revokeReused(ctx, stored)
+	}
+
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, common.InvalidToken
+	}
+
+	err = a.refreshRepo.MarkUsed(ctx, stored.ID)
+	if err == common.InvalidToken {
+		return nil, a.revokeReused(ctx, stored)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return a.issue(ctx, stored.UserID, stored.FamilyID)
+}
+
+func (a *AuthUsecase) Logout(ctx context.Context, refreshToken string) error {
+	if refreshToken == "" {
+		return common.BadRequest
+	}
+
+	stored, err := a.refreshRepo.GetByHash(ctx, token.HashOpaque(refreshToken))
+	if err == common.InvalidToken {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return a.refreshRepo.RevokeFamily(ctx, stored.FamilyID)
+}
+
+// ForgotPassword sends a reset token to the user. Unknown emails are not
+// reported so the endpoint cannot be used to discover accounts.
+func (a *AuthUsecase) ForgotPassword(ctx context.Context, email string) error {
+	if strings.TrimSpace(email) == "" {
+		return common.BadRequest
+	}
+
+	email, err := a.emails.Normalize(email)
+	if err != nil {
+		return nil
+	}
+
+	user, err := a.userRepo.GetByEmail(ctx, email)
+	if err == common.UserNotExist {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	err = a.userTokenRepo.InvalidateAll(ctx, user.ID, domain.TokenPurposePasswordReset)
+	if err != nil {
+		return err
+	}
+
+	resetToken, hash, err := token.NewOpaque()
+	if err != nil {
+		return common.ServerError
+	}
+
+	_, err = a.userTokenRepo.Create(ctx, &models.UserToken{
+		UserID:    user.ID,
+		Purpose:   domain.TokenPurposePasswordReset,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(a.tokens.PasswordResetTTL()),
+	})
+	if err != nil {
+		return err
+	}
+
+	err = a.notifier.Send(ctx, &domain.Notification{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body:    fmt.Sprintf("Use this token to reset your password: %s\nIt expires in %s.", resetToken, a.tokens.PasswordResetTTL()),
+	})
+	if err != nil {
+		return common.ServerError
+	}
+
+	return nil
+}
+
+// ResetPassword consumes the reset token, stores the new password and signs
+// the user out everywhere.
+func (a *AuthUsecase) ResetPassword(ctx context.Context, request *domain.PasswordResetRequest) error {
+	if request.Token == "" || strings.TrimSpace(request.Password) == "" {
+		return common.BadRequest
+	}
+
+	stored, err := a.userTokenRepo.GetByHash(ctx, domain.TokenPurposePasswordReset, token.HashOpaque(request.Token))
+	if err != nil {
+		return err
+	}
+
+	if stored.UsedAt.Valid || time.Now().After(stored.ExpiresAt) {
+		return common.InvalidToken
+	}
+
+	user, err := a.userRepo.GetByID(ctx, stored.UserID)
+	if err != nil {
+		return err
+	}
+
+	// The token stays usable until the new password is known to be
+	// acceptable, so a rejected password can be retried with the same link.
+	err = a.passwords.Validate(ctx, a.screener, request.Password, user.Name, user.Email)
+	if err != nil {
+		return err
+	}
+
+	hashed, err := a.hasher.HashPassword(request.Password)
+	if err != nil {
+		return common.BadRequest
+	}
+
+	err = a.userTokenRepo.MarkUsed(ctx, stored.ID)
+	if err != nil {
+		return err
+	}
+
+	err = a.userRepo.UpdatePassword(ctx, stored.UserID, hashed)
+	if err != nil {
+		return err
+	}
+
+	err = a.userTokenRepo.InvalidateAll(ctx, stored.UserID, domain.TokenPurposePasswordReset)
+	if err != nil {
+		return err
+	}
+
+	return a.refreshRepo.RevokeAllForUser(ctx, stored.UserID)
+}
+
+func (a *AuthUsecase) Authenticate(ctx context.Context, accessToken string) (*domain.Principal, error) {
+	claims, err := a.tokens.Parse(accessToken)
+	if err != nil {
+		return nil, common.InvalidToken
+	}
+
+	userID, err := claims.UserID()
+	if err != nil {
+		return nil, common.InvalidToken
+	}
+
+	user, err := a.userRepo.GetByID(ctx, userID)
+	if err == common.UserNotExist {
+		return nil, common.InvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	permissions, err := a.roleRepo.GetPermissions(ctx, user.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Principal{
+		UserID:      user.ID,
+		Role:        user.Role,
+		Permissions: permissions,
+	}, nil
+}
+
+func (a *AuthUsecase) issue(ctx context.Context, userID int, familyID string) (*domain.TokenResponse, error) {
+	accessToken, _, err := a.tokens.Sign(userID)
+	if err != nil {
+		return nil, common.ServerError
+	}
+
+	refreshToken, hash, err := token.NewOpaque()
+	if err != nil {
+		return nil, common.ServerError
+	}
+
+	_, err = a.refreshRepo.Create(ctx, &models.RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(a.tokens.RefreshTTL()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    TokenType,
+		ExpiresIn:    int(a.tokens.TTL().Seconds()),
+	}, nil
+}
+
+// checkAttempts rejects the login while the account or the source address is
+// blocked by earlier failures.
+func (a *AuthUsecase) checkAttempts(ctx context.Context, email string, ip string) error {
+	attempt, err := a.attemptRepo.Get(ctx, lockout.EmailKey(email))
+	if err != nil {
+		return err
+	}
+	if attempt.BlockedUntil.Valid && time.Now().Before(attempt.BlockedUntil.Time) {
+		if attempt.Locked {
+			return common.AccountLocked
+		}
+		return common.TooManyAttempts
+	}
+
+	if ip == "" || !a.lockout.TracksIP() {
+		return nil
+	}
+
+	attempt, err = a.attemptRepo.Get(ctx, lockout.IPKey(ip))
+	if err != nil {
+		return err
+	}
+	if attempt.BlockedUntil.Valid && time.Now().Before(attempt.BlockedUntil.Time) {
+		return common.TooManyAttempts
+	}
+
+	return nil
+}
+
+// recordFailure counts a failed check against the account and the source
+// address and returns cause, the error the caller should report.
+func (a *AuthUsecase) recordFailure(ctx context.Context, email string, ip string, cause error) error {
+	key := lockout.EmailKey(email)
+	failures, err := a.attemptRepo.RecordFailure(ctx, key, a.lockout.Window())
+	if err != nil {
+		return err
+	}
+
+	delay, locked := a.lockout.Account(failures)
+	err = a.attemptRepo.Block(ctx, key, time.Now().Add(delay), locked)
+	if err != nil {
+		return err
+	}
+	if locked {
+		logging.FromContext(ctx).Warn("Account locked after failed logins", zap.String("key", key), zap.Int("failures", failures))
+	}
+
+	if ip == "" || !a.lockout.TracksIP() {
+		return cause
+	}
+
+	key = lockout.IPKey(ip)
+	failures, err = a.attemptRepo.RecordFailure(ctx, key, a.lockout.Window())
+	if err != nil {
+		return err
+	}
+
+	err = a.attemptRepo.Block(ctx, key, time.Now().Add(a.lockout.IP(failures)), false)
+	if err != nil {
+		return err
+	}
+
+	return cause
+}
+
+// rehash replaces an outdated password hash. The login goes ahead even if the
+// upgrade fails, it is retried on the next one.
+func (a *AuthUsecase) rehash(ctx context.Context, userID int, password string) {
+	hash, err := a.hasher.HashPassword(password)
+	if err == nil {
+		err = a.userRepo.UpdatePassword(ctx, userID, hash)
+	}
+	if err != nil {
+		logging.FromContext(ctx).Warn("Password hash could not be upgraded", zap.Error(err), zap.Int("user_id", userID))
+	}
+}
+
+func (a *AuthUsecase) challenge(ctx context.Context, userID int) (*domain.TokenResponse, error) {
+	challengeToken, hash, err := token.NewOpaque()
+	if err != nil {
+		return nil, common.ServerError
+	}
+
+	_, err = a.userTokenRepo.Create(ctx, &models.UserToken{
+		UserID:    userID,
+		Purpose:   domain.TokenPurposeTwoFactor,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(a.totp.ChallengeTTL()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.TokenResponse{
+		TwoFactorRequired: true,
+		ChallengeToken:    challengeToken,
+	}, nil
+}
+
+func (a *AuthUsecase) verifySecondFactor(ctx context.Context, user *models.User, code string) error {
+	err := a.verifyTOTP(ctx, user, code)
+	if err != common.InvalidTwoFactorCode {
+		return err
+	}
+
+	return a.recoveryRepo.Use(ctx, user.ID, token.HashOpaque(totp.NormalizeRecoveryCode(code)))
+}
+
+func (a *AuthUsecase) verifyTOTP(ctx context.Context, user *models.User, code string) error {
+	secret, err := a.totp.Open(user.TotpSecret.String)
+	if err != nil {
+		logging.FromContext(ctx).Error("TOTP secret could not be opened", zap.Error(err), zap.Int("user_id", user.ID))
+		return common.ServerError
+	}
+
+	counter, ok := a.totp.Match(secret, strings.TrimSpace(code), time.Now())
+	if !ok {
+		return common.InvalidTwoFactorCode
+	}
+
+	return a.userRepo.UseTOTPCounter(ctx, user.ID, counter)
+}
+
+func (a *AuthUsecase) revokeReused(ctx context.Context, stored *models.RefreshToken) error {
+	logging.FromContext(ctx).Warn("Refresh token reuse detected", zap.Int("user_id", stored.UserID), zap.String("family_id", stored.FamilyID))
+	if err := a.refreshRepo.RevokeFamily(ctx, stored.FamilyID); err != nil {
+		return err
+	}
+	return common.InvalidToken
+}
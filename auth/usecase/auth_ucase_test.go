@@ -0,0 +1,723 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/h4yfans/case-study/common"
+	"github.com/h4yfans/case-study/common/emailaddr"
+	"github.com/h4yfans/case-study/common/lockout"
+	"github.com/h4yfans/case-study/common/password"
+	"github.com/h4yfans/case-study/common/token"
+	"github.com/h4yfans/case-study/common/totp"
+	"github.com/h4yfans/case-study/domain"
+	mocks "github.com/h4yfans/case-study/mocks/domain"
+	"github.com/h4yfans/case-study/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/volatiletech/null/v8"
+)
+
+type authMocks struct {
+	userRepo      *mocks.UserRepository
+	refreshRepo   *mocks.RefreshTokenRepository
+	roleRepo      *mocks.RoleRepository
+	userTokenRepo *mocks.UserTokenRepository
+	recoveryRepo  *mocks.RecoveryCodeRepository
+	attemptRepo   *mocks.LoginAttemptRepository
+	hasher        *mocks.PasswordHasher
+	passwords     *password.Policy
+	screener      *mocks.PasswordScreener
+	notifier      *mocks.Notifier
+	tokens        *token.Manager
+	totp          *totp.Manager
+	lockout       *lockout.Policy
+	emails        *emailaddr.Normalizer
+
+	requireVerifiedEmail bool
+}
+
+func newAuthMocks() *authMocks {
+	return &authMocks{
+		userRepo:      new(mocks.UserRepository),
+		refreshRepo:   new(mocks.RefreshTokenRepository),
+		roleRepo:      new(mocks.RoleRepository),
+		userTokenRepo: new(mocks.UserTokenRepository),
+		recoveryRepo:  new(mocks.RecoveryCodeRepository),
+		attemptRepo:   new(mocks.LoginAttemptRepository),
+		hasher:        new(mocks.PasswordHasher),
+		passwords:     password.NewPolicy(password.Config{MinLength: 8, RequireDigit: true}),
+		screener:      new(mocks.PasswordScreener),
+		notifier:      new(mocks.Notifier),
+		tokens: token.NewManager(token.Config{
+			Algorithm:        token.HS256,
+			Secret:           "secret",
+			Issuer:           "test",
+			AccessTokenTTL:   time.Minute,
+			RefreshTokenTTL:  time.Hour,
+			PasswordResetTTL: time.Hour,
+		}),
+		totp: totp.NewManager(totp.Config{
+			Issuer:        "test",
+			EncryptionKey: "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=",
+			ChallengeTTL:  time.Minute,
+		}),
+		lockout: lockout.NewPolicy(lockout.Config{
+			Threshold:   3,
+			IPThreshold: 10,
+			Duration:    time.Minute,
+			BackoffBase: time.Second,
+		}),
+		emails: emailaddr.NewNormalizer(emailaddr.Config{}),
+	}
+}
+
+func (m *authMocks) usecase() *AuthUsecase {
+	return NewAuthUsecase(m.userRepo, m.refreshRepo, m.roleRepo, m.userTokenRepo, m.recoveryRepo, m.attemptRepo, m.hasher, m.passwords, m.screener, m.notifier, m.tokens, m.totp, m.lockout, m.emails, m.requireVerifiedEmail)
+}
+
+// allowAttempts expects the lockout check for key to find no earlier failures.
+func (m *authMocks) allowAttempts(key string) {
+	m.attemptRepo.On("Get", context.Background(), key).Return(&models.LoginAttempt{Key: key}, nil)
+}
+
+// expectFailure expects a failed check to be counted against key.
+func (m *authMocks) expectFailure(key string, failures int, locked bool) {
+	m.attemptRepo.On("RecordFailure", context.Background(), key, time.Minute).Return(failures, nil)
+	m.attemptRepo.On("Block", context.Background(), key, mock.AnythingOfType("time.Time"), locked).Return(nil)
+}
+
+func (m *authMocks) AssertExpectations(t *testing.T) {
+	m.userRepo.AssertExpectations(t)
+	m.refreshRepo.AssertExpectations(t)
+	m.roleRepo.AssertExpectations(t)
+	m.userTokenRepo.AssertExpectations(t)
+	m.recoveryRepo.AssertExpectations(t)
+	m.attemptRepo.AssertExpectations(t)
+	m.hasher.AssertExpectations(t)
+	m.screener.AssertExpectations(t)
+	m.notifier.AssertExpectations(t)
+}
+
+func TestLogin(t *testing.T) {
+	user := &models.User{
+		ID:       1,
+		Name:     "Kaan",
+		Email:    "kaan@test.com",
+		Password: "$2a$04$hash",
+	}
+
+	userKey := lockout.EmailKey(user.Email)
+
+	t.Run("should return token", func(t *testing.T) {
+		m := newAuthMocks()
+		m.allowAttempts(userKey)
+		m.userRepo.On("GetByEmail", context.Background(), user.Email).Return(user, nil)
+		m.hasher.On("VerifyPassword", user.Password, "123123").Return(true, false, nil)
+		m.attemptRepo.On("Reset", context.Background(), userKey).Return(nil)
+		m.refreshRepo.On("Create", context.Background(), mock.AnythingOfType("*models.RefreshToken")).Return(&models.RefreshToken{}, nil)
+
+		res, err := m.usecase().Login(context.Background(), &domain.LoginRequest{Email: user.Email, Password: "123123"})
+		assert.NoError(t, err)
+		assert.Equal(t, TokenType, res.TokenType)
+		assert.Equal(t, 60, res.ExpiresIn)
+		assert.NotEmpty(t, res.RefreshToken)
+
+		claims, err := m.tokens.Parse(res.AccessToken)
+		assert.NoError(t, err)
+		userID, err := claims.UserID()
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, userID)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("should upgrade outdated hash", func(t *testing.T) {
+		m := newAuthMocks()
+		m.allowAttempts(userKey)
+		m.userRepo.On("GetByEmail", context.Background(), user.Email).Return(user, nil)
+		m.hasher.On("VerifyPassword", user.Password, "123123").Return(true, true, nil)
+		m.hasher.On("HashPassword", "123123").Return("upgraded", nil)
+		m.userRepo.On("UpdatePassword", context.Background(), user.ID, "upgraded").Return(nil)
+		m.attemptRepo.On("Reset", context.Background(), userKey).Return(nil)
+		m.refreshRepo.On("Create", context.Background(), mock.AnythingOfType("*models.RefreshToken")).Return(&models.RefreshToken{}, nil)
+
+		res, err := m.usecase().Login(context.Background(), &domain.LoginRequest{Email: user.Email, Password: "123123"})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, res.AccessToken)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("should reject wrong password", func(t *testing.T) {
+		m := newAuthMocks()
+		m.allowAttempts(userKey)
+		m.allowAttempts(lockout.IPKey("10.0.0.1"))
+		m.userRepo.On("GetByEmail", context.Background(), user.Email).Return(user, nil)
+		m.hasher.On("VerifyPassword", user.Password, "wrong").Return(false, false, nil)
+		m.expectFailure(userKey, 1, false)
+		m.expectFailure(lockout.IPKey("10.0.0.1"), 1, false)
+
+		res, err := m.usecase().Login(context.Background(), &domain.LoginRequest{Email: user.Email, Password: "wrong", IP: "10.0.0.1"})
+		assert.Equal(t, common.InvalidCredentials, err)
+		assert.Nil(t, res)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("should not track source address without an IP threshold", func(t *testing.T) {
+		m := newAuthMocks()
+		m.lockout = lockout.NewPolicy(lockout.Config{Threshold: 3, Duration: time.Minute, BackoffBase: time.Second})
+		m.allowAttempts(userKey)
+		m.userRepo.On("GetByEmail", context.Background(), user.Email).Return(user, nil)
+		m.hasher.On("VerifyPassword", user.Password, "wrong").Return(false, false, nil)
+		m.expectFailure(userKey, 1, false)
+
+		res, err := m.usecase().Login(context.Background(), &domain.LoginRequest{Email: user.Email, Password: "wrong", IP: "10.0.0.1"})
+		assert.Equal(t, common.InvalidCredentials, err)
+		assert.Nil(t, res)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("should lock account at threshold", func(t *testing.T) {
+		m := newAuthMocks()
+		m.allowAttempts(userKey)
+		m.userRepo.On("GetByEmail", context.Background(), user.Email).Return(user, nil)
+		m.hasher.On("VerifyPassword", user.Password, "wrong").Return(false, false, nil)
+		m.expectFailure(userKey, 3, true)
+
+		res, err := m.usecase().Login(context.Background(), &domain.LoginRequest{Email: user.Email, Password: "wrong"})
+		assert.Equal(t, common.InvalidCredentials, err)
+		assert.Nil(t, res)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("should reject locked account", func(t *testing.T) {
+		m := newAuthMocks()
+		m.attemptRepo.On("Get", context.Background(), userKey).Return(&models.LoginAttempt{
+			Key:          userKey,
+			Failures:     3,
+			BlockedUntil: null.TimeFrom(time.Now().Add(time.Minute)),
+			Locked:       true,
+		}, nil)
+
+		res, err := m.usecase().Login(context.Background(), &domain.LoginRequest{Email: user.Email, Password: "123123"})
+		assert.Equal(t, common.AccountLocked, err)
+		assert.Nil(t, res)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("should back off after failure", func(t *testing.T) {
+		m := newAuthMocks()
+		m.allowAttempts(userKey)
+		m.attemptRepo.On("Get", context.Background(), lockout.IPKey("10.0.0.1")).Return(&models.LoginAttempt{
+			Key:          lockout.IPKey("10.0.0.1"),
+			Failures:     2,
+			BlockedUntil: null.TimeFrom(time.Now().Add(time.Second)),
+		}, nil)
+
+		res, err := m.usecase().Login(context.Background(), &domain.LoginRequest{Email: user.Email, Password: "123123", IP: "10.0.0.1"})
+		assert.Equal(t, common.TooManyAttempts, err)
+		assert.Nil(t, res)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("should reject unverified email", func(t *testing.T) {
+		m := newAuthMocks()
+		m.requireVerifiedEmail = true
+		m.allowAttempts(userKey)
+		m.userRepo.On("GetByEmail", context.Background(), user.Email).Return(user, nil)
+		m.hasher.On("VerifyPassword", user.Password, "123123").Return(true, false, nil)
+
+		res, err := m.usecase().Login(context.Background(), &domain.LoginRequest{Email: user.Email, Password: "123123"})
+		assert.Equal(t, common.EmailNotVerified, err)
+		assert.Nil(t, res)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("should return two factor challenge", func(t *testing.T) {
+		m := newAuthMocks()
+		twoFactorUser := *user
+		twoFactorUser.TotpEnabledAt = null.TimeFrom(time.Now())
+		m.allowAttempts(userKey)
+		m.userRepo.On("GetByEmail", context.Background(), user.Email).Return(&twoFactorUser, nil)
+		m.hasher.On("VerifyPassword", user.Password, "123123").Return(true, false, nil)
+		m.userTokenRepo.On("Create", context.Background(), mock.MatchedBy(func(ut *models.UserToken) bool {
+			return ut.UserID == user.ID && ut.Purpose == domain.TokenPurposeTwoFactor
+		})).Return(&models.UserToken{}, nil)
+
+		res, err := m.usecase().Login(context.Background(), &domain.LoginRequest{Email: user.Email, Password: "123123"})
+		assert.NoError(t, err)
+		assert.True(t, res.TwoFactorRequired)
+		assert.NotEmpty(t, res.ChallengeToken)
+		assert.Empty(t, res.AccessToken)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("should reject unknown email", func(t *testing.T) {
+		m := newAuthMocks()
+		m.allowAttempts(lockout.EmailKey("nobody@test.com"))
+		m.userRepo.On("GetByEmail", context.Background(), "nobody@test.com").Return(nil, common.UserNotExist)
+		m.hasher.On("HashPassword", "123123").Return("hash", nil)
+		m.expectFailure(lockout.EmailKey("nobody@test.com"), 1, false)
+
+		res, err := m.usecase().Login(context.Background(), &domain.LoginRequest{Email: "nobody@test.com", Password: "123123"})
+		assert.Equal(t, common.InvalidCredentials, err)
+		assert.Nil(t, res)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("should not count a failed lookup as a failed login", func(t *testing.T) {
+		m := newAuthMocks()
+		m.allowAttempts(userKey)
+		m.userRepo.On("GetByEmail", context.Background(), user.Email).Return(nil, common.Timeout)
+
+		res, err := m.usecase().Login(context.Background(), &domain.LoginRequest{Email: user.Email, Password: "123123"})
+		assert.Equal(t, common.Timeout, err)
+		assert.Nil(t, res)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("should reject empty credentials", func(t *testing.T) {
+		m := newAuthMocks()
+
+		res, err := m.usecase().Login(context.Background(), &domain.LoginRequest{})
+		assert.Equal(t, common.BadRequest, err)
+		assert.Nil(t, res)
+		m.AssertExpectations(t)
+	})
+}
+
+func TestAuthenticate(t *testing.T) {
+	user := &models.User{
+		ID:    1,
+		Name:  "Kaan",
+		Email: "kaan@test.com",
+		Role:  domain.RoleUser,
+	}
+
+	t.Run("should return principal", func(t *testing.T) {
+		m := newAuthMocks()
+		accessToken, _, err := m.tokens.Sign(user.ID)
+		assert.NoError(t, err)
+		m.userRepo.On("GetByID", context.Background(), user.ID).Return(user, nil)
+		m.roleRepo.On("GetPermissions", context.Background(), domain.RoleUser).Return([]string{domain.PermissionUsersRead}, nil)
+
+		principal, err := m.usecase().Authenticate(context.Background(), accessToken)
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, principal.UserID)
+		assert.Equal(t, domain.RoleUser, principal.Role)
+		assert.True(t, principal.Can(domain.PermissionUsersRead))
+		assert.False(t, principal.Can(domain.PermissionUsersReadAny))
+		m.AssertExpectations(t)
+	})
+
+	t.Run("should reject malformed token", func(t *testing.T) {
+		m := newAuthMocks()
+
+		principal, err := m.usecase().Authenticate(context.Background(), "not-a-token")
+		assert.Equal(t, common.InvalidToken, err)
+		assert.Nil(t, principal)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("should reject token of deleted user", func(t *testing.T) {
+		m := newAuthMocks()
+		accessToken, _, err := m.tokens.Sign(user.ID)
+		assert.NoError(t, err)
+		m.userRepo.On("GetByID", context.Background(), user.ID).Return(nil, common.UserNotExist)
+
+		principal, err := m.usecase().Authenticate(context.Background(), accessToken)
+		assert.Equal(t, common.InvalidToken, err)
+		assert.Nil(t, principal)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("should not reject token when user could not be read", func(t *testing.T) {
+		m := newAuthMocks()
+		accessToken, _, err := m.tokens.Sign(user.ID)
+		assert.NoError(t, err)
+		m.userRepo.On("GetByID", context.Background(), user.ID).Return(nil, common.Timeout)
+
+		principal, err := m.usecase().Authenticate(context.Background(), accessToken)
+		assert.Equal(t, common.Timeout, err)
+		assert.Nil(t, principal)
+		m.AssertExpectations(t)
+	})
+}
+
+func TestRefresh(t *testing.T) {
+	refreshToken := "refresh-token"
+	hash := token.HashOpaque(refreshToken)
+
+	t.Run("should rotate token", func(t *testing.T) {
+		stored := &models.RefreshToken{ID: 1, UserID: 1, FamilyID: "family", TokenHash: hash, ExpiresAt: time.Now().Add(time.Hour)}
+
+		m := newAuthMocks()
+		m.refreshRepo.On("GetByHash", context.Background(), hash).Return(stored, nil)
+		m.refreshRepo.On("MarkUsed", context.Background(), stored.ID).Return(nil)
+		m.refreshRepo.On("Create", context.Background(), mock.MatchedBy(func(rt *models.RefreshToken) bool {
+			return rt.FamilyID == stored.FamilyID && rt.UserID == stored.UserID && rt.TokenHash != hash
+		})).Return(&models.RefreshToken{}, nil)
+
+		res, err := m.usecase().Refresh(context.Background(), refreshToken)
+		assert.NoError(t, err)
+		assert.NotEqual(t, refreshToken, res.RefreshToken)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("should revoke family on reuse", func(t *testing.T) {
+		stored := &models.RefreshToken{ID: 1, UserID: 1, FamilyID: "family", TokenHash: hash, ExpiresAt: time.Now().Add(time.Hour), UsedAt: null.TimeFrom(time.Now())}
+
+		m := newAuthMocks()
+		m.refreshRepo.On("GetByHash", context.Background(), hash).Return(stored, nil)
+		m.refreshRepo.On("RevokeFamily", context.Background(), stored.FamilyID).Return(nil)
+
+		res, err := m.usecase().Refresh(context.Background(), refreshToken)
+		assert.Equal(t, common.InvalidToken, err)
+		assert.Nil(t, res)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("should revoke family when concurrent use wins", func(t *testing.T) {
+		stored := &models.RefreshToken{ID: 1, UserID: 1, FamilyID: "family", TokenHash: hash, ExpiresAt: time.Now().Add(time.Hour)}
+
+		m := newAuthMocks()
+		m.refreshRepo.On("GetByHash", context.Background(), hash).Return(stored, nil)
+		m.refreshRepo.On("MarkUsed", context.Background(), stored.ID).Return(common.InvalidToken)
+		m.refreshRepo.On("RevokeFamily", context.Background(), stored.FamilyID).Return(nil)
+
+		res, err := m.usecase().Refresh(context.Background(), refreshToken)
+		assert.Equal(t, common.InvalidToken, err)
+		assert.Nil(t, res)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("should reject expired token", func(t *testing.T) {
+		stored := &models.RefreshToken{ID: 1, UserID: 1, FamilyID: "family", TokenHash: hash, ExpiresAt: time.Now().Add(-time.Hour)}
+
+		m := newAuthMocks()
+		m.refreshRepo.On("GetByHash", context.Background(), hash).Return(stored, nil)
+
+		res, err := m.usecase().Refresh(context.Background(), refreshToken)
+		assert.Equal(t, common.InvalidToken, err)
+		assert.Nil(t, res)
+		m.AssertExpectations(t)
+	})
+}
+
+func TestLogout(t *testing.T) {
+	refreshToken := "refresh-token"
+	hash := token.HashOpaque(refreshToken)
+
+	t.Run("should revoke family", func(t *testing.T) {
+		stored := &models.RefreshToken{ID: 1, UserID: 1, FamilyID: "family", TokenHash: hash}
+
+		m := newAuthMocks()
+		m.refreshRepo.On("GetByHash", context.Background(), hash).Return(stored, nil)
+		m.refreshRepo.On("RevokeFamily", context.Background(), stored.FamilyID).Return(nil)
+
+		err := m.usecase().Logout(context.Background(), refreshToken)
+		assert.NoError(t, err)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("should ignore unknown token", func(t *testing.T) {
+		m := newAuthMocks()
+		m.refreshRepo.On("GetByHash", context.Background(), hash).Return(nil, common.InvalidToken)
+
+		err := m.usecase().Logout(context.Background(), refreshToken)
+		assert.NoError(t, err)
+		m.AssertExpectations(t)
+	})
+}
+
+func TestForgotPassword(t *testing.T) {
+	user := &models.User{
+		ID:    1,
+		Name:  "Kaan",
+		Email: "kaan@test.com",
+	}
+
+	t.Run("should send reset token", func(t *testing.T) {
+		m := newAuthMocks()
+		m.userRepo.On("GetByEmail", context.Background(), user.Email).Return(user, nil)
+		m.userTokenRepo.On("InvalidateAll", context.Background(), user.ID, domain.TokenPurposePasswordReset).Return(nil)
+		m.userTokenRepo.On("Create", context.Background(), mock.MatchedBy(func(ut *models.UserToken) bool {
+			return ut.UserID == user.ID && ut.Purpose == domain.TokenPurposePasswordReset && ut.TokenHash != ""
+		})).Return(&models.UserToken{}, nil)
+		m.notifier.On("Send", context.Background(), mock.MatchedBy(func(n *domain.Notification) bool {
+			return n.To == user.Email
+		})).Return(nil)
+
+		err := m.usecase().ForgotPassword(context.Background(), user.Email)
+		assert.NoError(t, err)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("should not reveal unknown email", func(t *testing.T) {
+		m := newAuthMocks()
+		m.userRepo.On("GetByEmail", context.Background(), "nobody@test.com").Return(nil, common.UserNotExist)
+
+		err := m.usecase().ForgotPassword(context.Background(), "nobody@test.com")
+		assert.NoError(t, err)
+		m.AssertExpectations(t)
+	})
+}
+
+func TestResetPassword(t *testing.T) {
+	resetToken := "reset-token"
+	hash := token.HashOpaque(resetToken)
+	user := &models.User{ID: 1, Name: "Kaan", Email: "kaan@test.com"}
+
+	t.Run("should reset password and revoke sessions", func(t *testing.T) {
+		stored := &models.UserToken{ID: 1, UserID: 1, Purpose: domain.TokenPurposePasswordReset, TokenHash: hash, ExpiresAt: time.Now().Add(time.Hour)}
+
+		m := newAuthMocks()
+		m.userTokenRepo.On("GetByHash", context.Background(), domain.TokenPurposePasswordReset, hash).Return(stored, nil)
+		m.userRepo.On("GetByID", context.Background(), stored.UserID).Return(user, nil)
+		m.screener.On("Breached", context.Background(), "new-password-42").Return(false, nil)
+		m.hasher.On("HashPassword", "new-password-42").Return("hashed", nil)
+		m.userTokenRepo.On("MarkUsed", context.Background(), stored.ID).Return(nil)
+		m.userRepo.On("UpdatePassword", context.Background(), stored.UserID, "hashed").Return(nil)
+		m.userTokenRepo.On("InvalidateAll", context.Background(), stored.UserID, domain.TokenPurposePasswordReset).Return(nil)
+		m.refreshRepo.On("RevokeAllForUser", context.Background(), stored.UserID).Return(nil)
+
+		err := m.usecase().ResetPassword(context.Background(), &domain.PasswordResetRequest{Token: resetToken, Password: "new-password-42"})
+		assert.NoError(t, err)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("should reject a password the policy forbids without using the token", func(t *testing.T) {
+		stored := &models.UserToken{ID: 1, UserID: 1, Purpose: domain.TokenPurposePasswordReset, TokenHash: hash, ExpiresAt: time.Now().Add(time.Hour)}
+
+		m := newAuthMocks()
+		m.userTokenRepo.On("GetByHash", context.Background(), domain.TokenPurposePasswordReset, hash).Return(stored, nil)
+		m.userRepo.On("GetByID", context.Background(), stored.UserID).Return(user, nil)
+		m.screener.On("Breached", context.Background(), "a").Return(false, nil)
+
+		err := m.usecase().ResetPassword(context.Background(), &domain.PasswordResetRequest{Token: resetToken, Password: "a"})
+		var validationErr *common.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+		m.userTokenRepo.AssertNotCalled(t, "MarkUsed", mock.Anything, mock.Anything)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("should reject a breached password", func(t *testing.T) {
+		stored := &models.UserToken{ID: 1, UserID: 1, Purpose: domain.TokenPurposePasswordReset, TokenHash: hash, ExpiresAt: time.Now().Add(time.Hour)}
+
+		m := newAuthMocks()
+		m.userTokenRepo.On("GetByHash", context.Background(), domain.TokenPurposePasswordReset, hash).Return(stored, nil)
+		m.userRepo.On("GetByID", context.Background(), stored.UserID).Return(user, nil)
+		m.screener.On("Breached", context.Background(), "password123").Return(true, nil)
+
+		err := m.usecase().ResetPassword(context.Background(), &domain.PasswordResetRequest{Token: resetToken, Password: "password123"})
+		var validationErr *common.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+		m.userTokenRepo.AssertNotCalled(t, "MarkUsed", mock.Anything, mock.Anything)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("should keep the token when hashing fails", func(t *testing.T) {
+		stored := &models.UserToken{ID: 1, UserID: 1, Purpose: domain.TokenPurposePasswordReset, TokenHash: hash, ExpiresAt: time.Now().Add(time.Hour)}
+
+		m := newAuthMocks()
+		m.userTokenRepo.On("GetByHash", context.Background(), domain.TokenPurposePasswordReset, hash).Return(stored, nil)
+		m.userRepo.On("GetByID", context.Background(), stored.UserID).Return(user, nil)
+		m.screener.On("Breached", context.Background(), "new-password-42").Return(false, nil)
+		m.hasher.On("HashPassword", "new-password-42").Return("", errors.New("hash failed"))
+
+		err := m.usecase().ResetPassword(context.Background(), &domain.PasswordResetRequest{Token: resetToken, Password: "new-password-42"})
+		assert.Equal(t, common.BadRequest, err)
+		m.userTokenRepo.AssertNotCalled(t, "MarkUsed", mock.Anything, mock.Anything)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("should reject used token", func(t *testing.T) {
+		stored := &models.UserToken{ID: 1, UserID: 1, Purpose: domain.TokenPurposePasswordReset, TokenHash: hash, ExpiresAt: time.Now().Add(time.Hour), UsedAt: null.TimeFrom(time.Now())}
+
+		m := newAuthMocks()
+		m.userTokenRepo.On("GetByHash", context.Background(), domain.TokenPurposePasswordReset, hash).Return(stored, nil)
+
+		err := m.usecase().ResetPassword(context.Background(), &domain.PasswordResetRequest{Token: resetToken, Password: "new-password"})
+		assert.Equal(t, common.InvalidToken, err)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("should reject expired token", func(t *testing.T) {
+		stored := &models.UserToken{ID: 1, UserID: 1, Purpose: domain.TokenPurposePasswordReset, TokenHash: hash, ExpiresAt: time.Now().Add(-time.Minute)}
+
+		m := newAuthMocks()
+		m.userTokenRepo.On("GetByHash", context.Background(), domain.TokenPurposePasswordReset, hash).Return(stored, nil)
+
+		err := m.usecase().ResetPassword(context.Background(), &domain.PasswordResetRequest{Token: resetToken, Password: "new-password"})
+		assert.Equal(t, common.InvalidToken, err)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("should reject blank password", func(t *testing.T) {
+		m := newAuthMocks()
+
+		err := m.usecase().ResetPassword(context.Background(), &domain.PasswordResetRequest{Token: resetToken, Password: " "})
+		assert.Equal(t, common.BadRequest, err)
+		m.AssertExpectations(t)
+	})
+}
+
+func TestLoginTwoFactor(t *testing.T) {
+	challengeToken := "challenge-token"
+	hash := token.HashOpaque(challengeToken)
+
+	m := newAuthMocks()
+	secret, err := m.totp.GenerateSecret()
+	assert.NoError(t, err)
+	sealed, err := m.totp.Seal(secret)
+	assert.NoError(t, err)
+
+	user := &models.User{ID: 1, Email: "kaan@test.com", TotpSecret: null.StringFrom(sealed), TotpEnabledAt: null.TimeFrom(time.Now())}
+	stored := &models.UserToken{ID: 7, UserID: 1, Purpose: domain.TokenPurposeTwoFactor, TokenHash: hash, ExpiresAt: time.Now().Add(time.Minute)}
+
+	t.Run("should accept totp code", func(t *testing.T) {
+		counter := time.Now().Unix() / int64(totp.Period.Seconds())
+		code, err := totp.Code(secret, counter)
+		assert.NoError(t, err)
+
+		m := newAuthMocks()
+		m.userTokenRepo.On("GetByHash", context.Background(), domain.TokenPurposeTwoFactor, hash).Return(stored, nil)
+		m.userRepo.On("GetByID", context.Background(), user.ID).Return(user, nil)
+		m.allowAttempts(lockout.EmailKey(user.Email))
+		m.userRepo.On("UseTOTPCounter", context.Background(), user.ID, counter).Return(nil)
+		m.userTokenRepo.On("MarkUsed", context.Background(), stored.ID).Return(nil)
+		m.attemptRepo.On("Reset", context.Background(), lockout.EmailKey(user.Email)).Return(nil)
+		m.refreshRepo.On("Create", context.Background(), mock.AnythingOfType("*models.RefreshToken")).Return(&models.RefreshToken{}, nil)
+
+		res, err := m.usecase().LoginTwoFactor(context.Background(), &domain.TwoFactorLoginRequest{ChallengeToken: challengeToken, Code: code})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, res.AccessToken)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("should accept recovery code", func(t *testing.T) {
+		m := newAuthMocks()
+		m.userTokenRepo.On("GetByHash", context.Background(), domain.TokenPurposeTwoFactor, hash).Return(stored, nil)
+		m.userRepo.On("GetByID", context.Background(), user.ID).Return(user, nil)
+		m.allowAttempts(lockout.EmailKey(user.Email))
+		m.recoveryRepo.On("Use", context.Background(), user.ID, token.HashOpaque("abcdefgh")).Return(nil)
+		m.userTokenRepo.On("MarkUsed", context.Background(), stored.ID).Return(nil)
+		m.attemptRepo.On("Reset", context.Background(), lockout.EmailKey(user.Email)).Return(nil)
+		m.refreshRepo.On("Create", context.Background(), mock.AnythingOfType("*models.RefreshToken")).Return(&models.RefreshToken{}, nil)
+
+		res, err := m.usecase().LoginTwoFactor(context.Background(), &domain.TwoFactorLoginRequest{ChallengeToken: challengeToken, Code: "ABCD-EFGH"})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, res.AccessToken)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("should reject invalid code", func(t *testing.T) {
+		m := newAuthMocks()
+		m.userTokenRepo.On("GetByHash", context.Background(), domain.TokenPurposeTwoFactor, hash).Return(stored, nil)
+		m.userRepo.On("GetByID", context.Background(), user.ID).Return(user, nil)
+		m.allowAttempts(lockout.EmailKey(user.Email))
+		m.recoveryRepo.On("Use", context.Background(), user.ID, token.HashOpaque("wrong")).Return(common.InvalidTwoFactorCode)
+		m.expectFailure(lockout.EmailKey(user.Email), 1, false)
+
+		res, err := m.usecase().LoginTwoFactor(context.Background(), &domain.TwoFactorLoginRequest{ChallengeToken: challengeToken, Code: "wrong"})
+		assert.Equal(t, common.InvalidTwoFactorCode, err)
+		assert.Nil(t, res)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("should reject expired challenge", func(t *testing.T) {
+		expired := *stored
+		expired.ExpiresAt = time.Now().Add(-time.Second)
+
+		m := newAuthMocks()
+		m.userTokenRepo.On("GetByHash", context.Background(), domain.TokenPurposeTwoFactor, hash).Return(&expired, nil)
+
+		res, err := m.usecase().LoginTwoFactor(context.Background(), &domain.TwoFactorLoginRequest{ChallengeToken: challengeToken, Code: "123456"})
+		assert.Equal(t, common.InvalidToken, err)
+		assert.Nil(t, res)
+		m.AssertExpectations(t)
+	})
+}
+
+func TestEnrollTOTP(t *testing.T) {
+	principal := &domain.Principal{UserID: 1, Role: domain.RoleUser}
+
+	t.Run("should return secret", func(t *testing.T) {
+		m := newAuthMocks()
+		m.userRepo.On("GetByID", context.Background(), 1).Return(&models.User{ID: 1, Email: "kaan@test.com"}, nil)
+		m.userRepo.On("SetTOTPSecret", context.Background(), 1, mock.AnythingOfType("string")).Return(nil)
+
+		res, err := m.usecase().EnrollTOTP(context.Background(), principal)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, res.Secret)
+		assert.Contains(t, res.URI, "otpauth://totp/")
+		assert.Contains(t, res.URI, "secret="+res.Secret)
+
+		sealed := m.userRepo.Calls[1].Arguments.String(2)
+		assert.NotEqual(t, res.Secret, sealed)
+		opened, err := m.totp.Open(sealed)
+		assert.NoError(t, err)
+		assert.Equal(t, res.Secret, opened)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("should reject enabled user", func(t *testing.T) {
+		m := newAuthMocks()
+		m.userRepo.On("GetByID", context.Background(), 1).Return(&models.User{ID: 1, TotpEnabledAt: null.TimeFrom(time.Now())}, nil)
+
+		res, err := m.usecase().EnrollTOTP(context.Background(), principal)
+		assert.Equal(t, common.TwoFactorEnabled, err)
+		assert.Nil(t, res)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("should require principal", func(t *testing.T) {
+		m := newAuthMocks()
+
+		res, err := m.usecase().EnrollTOTP(context.Background(), nil)
+		assert.Equal(t, common.Unauthorized, err)
+		assert.Nil(t, res)
+		m.AssertExpectations(t)
+	})
+}
+
+func TestConfirmTOTP(t *testing.T) {
+	principal := &domain.Principal{UserID: 1, Role: domain.RoleUser}
+
+	m := newAuthMocks()
+	secret, err := m.totp.GenerateSecret()
+	assert.NoError(t, err)
+	sealed, err := m.totp.Seal(secret)
+	assert.NoError(t, err)
+	user := &models.User{ID: 1, TotpSecret: null.StringFrom(sealed)}
+
+	t.Run("should enable and return recovery codes", func(t *testing.T) {
+		counter := time.Now().Unix() / int64(totp.Period.Seconds())
+		code, err := totp.Code(secret, counter)
+		assert.NoError(t, err)
+
+		m := newAuthMocks()
+		m.userRepo.On("GetByID", context.Background(), 1).Return(user, nil)
+		m.userRepo.On("UseTOTPCounter", context.Background(), 1, counter).Return(nil)
+		m.recoveryRepo.On("Replace", context.Background(), 1, mock.MatchedBy(func(hashes []string) bool {
+			return len(hashes) == totp.RecoveryCodeCount
+		})).Return(nil)
+		m.userRepo.On("EnableTOTP", context.Background(), 1).Return(nil)
+
+		res, err := m.usecase().ConfirmTOTP(context.Background(), principal, code)
+		assert.NoError(t, err)
+		assert.Len(t, res.RecoveryCodes, totp.RecoveryCodeCount)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("should reject invalid code", func(t *testing.T) {
+		m := newAuthMocks()
+		m.userRepo.On("GetByID", context.Background(), 1).Return(user, nil)
+
+		res, err := m.usecase().ConfirmTOTP(context.Background(), principal, "000000x")
+		assert.Equal(t, common.InvalidTwoFactorCode, err)
+		assert.Nil(t, res)
+		m.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,122 @@
+// Command emailduplicates reports accounts whose emails are the same address
+// once normalized, which the case insensitive email column does not allow.
+// Run it against a database before it is migrated to citext emails and merge
+// or rename the accounts it lists. It exits with status 1 if any are found.
+//
+// With -rewrite and no duplicates left, it then stores every email and
+// pending email in normalized form, which lookups expect: an address saved
+// with an internationalized domain cannot be found until it is rewritten.
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/h4yfans/case-study/common/db"
+	"github.com/h4yfans/case-study/common/emailaddr"
+	"github.com/h4yfans/case-study/common/environment"
+	"github.com/h4yfans/case-study/common/logging"
+	"go.uber.org/zap"
+)
+
+type account struct {
+	id           int
+	email        string
+	pendingEmail sql.NullString
+}
+
+func main() {
+	rewrite := flag.Bool("rewrite", false, "store normalized emails once there are no duplicates")
+	flag.Parse()
+
+	logging.Initialize()
+	defer logging.Close()
+
+	DB := db.Connect(environment.Database())
+	defer db.Close(DB)
+
+	normalizer := emailaddr.NewNormalizer(environment.EmailAddress())
+
+	rows, err := DB.Query("SELECT id, email, pending_email FROM users ORDER BY id")
+	if err != nil {
+		zap.L().Fatal("Users could not be read", zap.Error(err))
+	}
+	defer rows.Close()
+
+	var accounts []account
+	groups := make(map[string][]account)
+	for rows.Next() {
+		var a account
+		if err := rows.Scan(&a.id, &a.email, &a.pendingEmail); err != nil {
+			zap.L().Fatal("User could not be read", zap.Error(err))
+		}
+
+		normalized, err := normalizer.Normalize(a.email)
+		if err != nil {
+			fmt.Printf("user %d: %q is not a valid address\n", a.id, a.email)
+			normalized = a.email
+		}
+		key := emailaddr.Key(normalized)
+		groups[key] = append(groups[key], a)
+		accounts = append(accounts, a)
+	}
+	if err := rows.Err(); err != nil {
+		zap.L().Fatal("Users could not be read", zap.Error(err))
+	}
+
+	keys := make([]string, 0, len(groups))
+	for key, accounts := range groups {
+		if len(accounts) > 1 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fmt.Printf("%s:\n", key)
+		for _, a := range groups[key] {
+			fmt.Printf("  user %d: %s\n", a.id, a.email)
+		}
+	}
+
+	if len(keys) > 0 {
+		fmt.Printf("%d addresses are used by more than one account\n", len(keys))
+		os.Exit(1)
+	}
+	fmt.Println("no duplicate addresses")
+
+	if *rewrite {
+		fmt.Printf("%d emails rewritten\n", rewriteEmails(DB, normalizer, accounts))
+	}
+}
+
+// rewriteEmails stores the normalized form of every email and pending email
+// that is not stored that way yet and returns how many accounts changed.
+// Invalid addresses are left as they are.
+func rewriteEmails(DB *sql.DB, normalizer *emailaddr.Normalizer, accounts []account) int {
+	rewritten := 0
+	for _, a := range accounts {
+		email := a.email
+		if normalized, err := normalizer.Normalize(a.email); err == nil {
+			email = normalized
+		}
+		pendingEmail := a.pendingEmail
+		if normalized, err := normalizer.Normalize(a.pendingEmail.String); a.pendingEmail.Valid && err == nil {
+			pendingEmail.String = normalized
+		}
+		if email == a.email && pendingEmail == a.pendingEmail {
+			continue
+		}
+
+		_, err := DB.Exec("UPDATE users SET email = $1, pending_email = $2, updated_at = now() WHERE id = $3", email, pendingEmail, a.id)
+		if err != nil {
+			zap.L().Fatal("User could not be rewritten", zap.Error(err), zap.Int("id", a.id))
+		}
+		fmt.Printf("user %d: %s -> %s\n", a.id, a.email, email)
+		rewritten++
+	}
+	return rewritten
+}
@@ -0,0 +1,103 @@
+package breached
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/h4yfans/case-study/domain"
+	"go.uber.org/zap"
+)
+
+const prefixLength = 5
+
+type Config struct {
+	Path     string
+	MinCount int
+}
+
+// New returns the screener for the configured list. Screening is disabled when
+// no path is set.
+func New(config Config) domain.PasswordScreener {
+	if config.Path == "" {
+		zap.L().Warn("Breached password list is not configured, screening is disabled")
+		return NewDisabled()
+	}
+
+	info, err := os.Stat(config.Path)
+	if err != nil || !info.IsDir() {
+		zap.L().Fatal("Breached password list must be a directory", zap.Error(err), zap.String("path", config.Path))
+	}
+
+	return NewRangeDirectory(config.Path, config.MinCount)
+}
+
+type Disabled struct{}
+
+func NewDisabled() *Disabled {
+	return &Disabled{}
+}
+
+func (d *Disabled) Breached(ctx context.Context, password string) (bool, error) {
+	return false, nil
+}
+
+// RangeDirectory looks passwords up in a directory of k-anonymity range files,
+// the layout produced by the Have I Been Pwned downloader. A file named after
+// the first five hex characters of the SHA-1 hash, such as 21BD1.txt, lists
+// the remaining suffixes as SUFFIX:COUNT lines. Only the matching file is read
+// and it is streamed, so memory use does not grow with the list.
+type RangeDirectory struct {
+	path     string
+	minCount int
+}
+
+func NewRangeDirectory(path string, minCount int) *RangeDirectory {
+	if minCount < 1 {
+		minCount = 1
+	}
+	return &RangeDirectory{
+		path:     path,
+		minCount: minCount,
+	}
+}
+
+func (r *RangeDirectory) Breached(ctx context.Context, password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:prefixLength], hash[prefixLength:]
+
+	file, err := os.Open(filepath.Join(r.path, prefix+".txt"))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		parts := strings.SplitN(strings.TrimSpace(scanner.Text()), ":", 2)
+		if !strings.EqualFold(parts[0], suffix) {
+			continue
+		}
+
+		// Padding entries carry a count of zero and must not match.
+		count := 1
+		if len(parts) == 2 {
+			count, err = strconv.Atoi(parts[1])
+			if err != nil {
+				return false, err
+			}
+		}
+		return count >= r.minCount, nil
+	}
+
+	return false, scanner.Err()
+}
@@ -0,0 +1,50 @@
+package breached
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// SHA-1 of "password" is 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8 and of
+// "P@ssw0rd" is 21BD12DC183F740EE76F27B78EB39C8AD972A757.
+func newDirectory(t *testing.T, minCount int) *RangeDirectory {
+	dir, err := ioutil.TempDir("", "breached")
+	assert.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	err = ioutil.WriteFile(filepath.Join(dir, "5BAA6.txt"), []byte("003D68EB55068C33ACE09247EE4C639306B:3\r\n1E4C9B93F3F0682250B6CF8331B7EE68FD8:9545824\r\n"), 0644)
+	assert.NoError(t, err)
+	err = ioutil.WriteFile(filepath.Join(dir, "21BD1.txt"), []byte("2DC183F740EE76F27B78EB39C8AD972A757:0\n"), 0644)
+	assert.NoError(t, err)
+
+	return NewRangeDirectory(dir, minCount)
+}
+
+func TestBreached(t *testing.T) {
+	r := newDirectory(t, 1)
+
+	found, err := r.Breached(context.Background(), "password")
+	assert.NoError(t, err)
+	assert.True(t, found)
+
+	found, err = r.Breached(context.Background(), "P@ssw0rd")
+	assert.NoError(t, err)
+	assert.False(t, found, "padding entries do not match")
+
+	found, err = r.Breached(context.Background(), "a password missing from every range file")
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestBreachedMinCount(t *testing.T) {
+	r := newDirectory(t, 10000000)
+
+	found, err := r.Breached(context.Background(), "password")
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
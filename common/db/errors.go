@@ -0,0 +1,83 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// ErrorClass is the kind of failure behind a database error, as far as
+// callers can act on it.
+type ErrorClass int
+
+const (
+	// ClassUnknown covers every error not classified below, which callers
+	// should treat as a server error.
+	ClassUnknown ErrorClass = iota
+	ClassUniqueViolation
+	ClassForeignKeyViolation
+	ClassNotNullViolation
+	ClassCheckViolation
+	// ClassConflict is a transaction aborted by a concurrent one, a
+	// serialization failure or a deadlock, which may succeed when retried.
+	ClassConflict
+	// ClassTimeout is a statement canceled by its context or by
+	// statement_timeout, or one that gave up waiting for a lock.
+	ClassTimeout
+)
+
+// Postgres error codes, see
+// https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	codeUniqueViolation      = "23505"
+	codeForeignKeyViolation  = "23503"
+	codeNotNullViolation     = "23502"
+	codeCheckViolation       = "23514"
+	codeSerializationFailure = "40001"
+	codeDeadlockDetected     = "40P01"
+	codeLockNotAvailable     = "55P03"
+	codeQueryCanceled        = "57014"
+)
+
+// Classify tells what kind of failure err is. It looks through wrapping, so
+// errors returned by the generated models can be passed as they are.
+func Classify(err error) ErrorClass {
+	if err == nil {
+		return ClassUnknown
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return ClassTimeout
+	}
+
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return ClassUnknown
+	}
+
+	switch pqErr.Code {
+	case codeUniqueViolation:
+		return ClassUniqueViolation
+	case codeForeignKeyViolation:
+		return ClassForeignKeyViolation
+	case codeNotNullViolation:
+		return ClassNotNullViolation
+	case codeCheckViolation:
+		return ClassCheckViolation
+	case codeSerializationFailure, codeDeadlockDetected:
+		return ClassConflict
+	case codeLockNotAvailable, codeQueryCanceled:
+		return ClassTimeout
+	default:
+		return ClassUnknown
+	}
+}
+
+// Constraint returns the name of the constraint err violated, if any.
+func Constraint(err error) string {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Constraint
+	}
+	return ""
+}
@@ -0,0 +1,44 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(t *testing.T) {
+	cases := map[string]ErrorClass{
+		"23505": ClassUniqueViolation,
+		"23503": ClassForeignKeyViolation,
+		"23502": ClassNotNullViolation,
+		"23514": ClassCheckViolation,
+		"40001": ClassConflict,
+		"40P01": ClassConflict,
+		"55P03": ClassTimeout,
+		"57014": ClassTimeout,
+		"42601": ClassUnknown,
+	}
+	for code, class := range cases {
+		err := &pq.Error{Code: pq.ErrorCode(code)}
+		assert.Equal(t, class, Classify(err), code)
+	}
+}
+
+func TestClassifyWrapped(t *testing.T) {
+	err := fmt.Errorf("models: unable to insert into users: %w", &pq.Error{Code: "23505", Constraint: "users_email_key"})
+
+	assert.Equal(t, ClassUniqueViolation, Classify(err))
+	assert.Equal(t, "users_email_key", Constraint(err))
+}
+
+func TestClassifyContext(t *testing.T) {
+	assert.Equal(t, ClassTimeout, Classify(fmt.Errorf("query: %w", context.DeadlineExceeded)))
+	assert.Equal(t, ClassTimeout, Classify(context.Canceled))
+	assert.Equal(t, ClassUnknown, Classify(errors.New("connection refused")))
+	assert.Equal(t, ClassUnknown, Classify(nil))
+	assert.Equal(t, "", Constraint(errors.New("connection refused")))
+}
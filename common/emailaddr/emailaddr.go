@@ -0,0 +1,70 @@
+package emailaddr
+
+import (
+	"errors"
+	"net/mail"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+var ErrInvalid = errors.New("emailaddr: invalid address")
+
+// MaxLength is the longest address mail can be delivered to, the RFC 5321
+// path limit without its angle brackets.
+const MaxLength = 254
+
+type Config struct {
+	// FoldLocalPart lowercases the part before the @ too. Uniqueness is case
+	// insensitive either way; this only decides what is stored and shown.
+	FoldLocalPart bool
+}
+
+// Normalizer turns the addresses users type into the form that is stored and
+// looked up, so one mailbox cannot end up with two accounts.
+type Normalizer struct {
+	foldLocalPart bool
+}
+
+func NewNormalizer(config Config) *Normalizer {
+	return &Normalizer{foldLocalPart: config.FoldLocalPart}
+}
+
+// Normalize trims the address, converts its domain to lowercase ASCII, with
+// internationalized domains in their punycode form, and optionally lowercases
+// the local part. It reports ErrInvalid for anything but a bare address of at
+// most MaxLength bytes.
+func (n *Normalizer) Normalize(address string) (string, error) {
+	address = strings.TrimSpace(address)
+
+	at := strings.LastIndex(address, "@")
+	if at <= 0 || at == len(address)-1 {
+		return "", ErrInvalid
+	}
+
+	local, domain := address[:at], address[at+1:]
+	domain, err := idna.Lookup.ToASCII(domain)
+	if err != nil {
+		return "", ErrInvalid
+	}
+	if n.foldLocalPart {
+		local = strings.ToLower(local)
+	}
+
+	normalized := local + "@" + domain
+	if len(normalized) > MaxLength {
+		return "", ErrInvalid
+	}
+	parsed, err := mail.ParseAddress(normalized)
+	if err != nil || parsed.Name != "" || parsed.Address != normalized {
+		return "", ErrInvalid
+	}
+
+	return normalized, nil
+}
+
+// Key is what two addresses share when the database treats them as the same
+// one.
+func Key(address string) string {
+	return strings.ToLower(address)
+}
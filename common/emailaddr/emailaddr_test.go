@@ -0,0 +1,48 @@
+package emailaddr
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	n := NewNormalizer(Config{})
+
+	for input, expected := range map[string]string{
+		" Kaan@Example.COM ":   "Kaan@example.com",
+		"kaan@Bücher.example":  "kaan@xn--bcher-kva.example",
+		"kaan+tag@example.com": "kaan+tag@example.com",
+	} {
+		normalized, err := n.Normalize(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, normalized, input)
+	}
+}
+
+func TestNormalizeFoldLocalPart(t *testing.T) {
+	n := NewNormalizer(Config{FoldLocalPart: true})
+
+	normalized, err := n.Normalize("Kaan@Example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "kaan@example.com", normalized)
+}
+
+func TestNormalizeInvalid(t *testing.T) {
+	n := NewNormalizer(Config{})
+
+	for _, input := range []string{
+		"",
+		"kaan",
+		"@example.com",
+		"kaan@",
+		"Kaan <kaan@example.com>",
+		"kaan@exa mple.com",
+		"kaan@@example.com",
+		strings.Repeat("k", 64) + "@" + strings.Repeat("a.", 94) + "com",
+	} {
+		_, err := n.Normalize(input)
+		assert.Equal(t, ErrInvalid, err, input)
+	}
+}
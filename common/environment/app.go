@@ -9,11 +9,21 @@ import (
 	"go.uber.org/zap"
 )
 
+// EnvironmentLocal is the ENVIRONMENT of local development.
+const EnvironmentLocal = "local"
+
 const (
 	DefaultContextTimeout = time.Minute * 5 // 5 Minute
 	DefaultPort           = 8080
+	DefaultMetricsPort    = 9090
+
+	DefaultHealthCheckTimeout = time.Second * 2 // 2 Seconds
 )
 
+func Environment() string {
+	return strings.ToLower(os.Getenv("ENVIRONMENT"))
+}
+
 func Debug() bool {
 	return strings.ToUpper(os.Getenv("DEBUG")) == "TRUE"
 }
@@ -22,6 +32,10 @@ func BoilDebug() bool {
 	return strings.ToUpper(os.Getenv("BOIL_DEBUG")) == "TRUE"
 }
 
+func RequireEmailVerification() bool {
+	return strings.ToUpper(os.Getenv("REQUIRE_EMAIL_VERIFICATION")) == "TRUE"
+}
+
 func ContextTimeout() time.Duration {
 	env := os.Getenv("CONTEXT_TIMEOUT")
 	if env == "" {
@@ -46,3 +60,30 @@ func Port() int {
 	}
 	return port
 }
+
+// MetricsPort is the port of the internal listener that serves /metrics, kept
+// apart from the public one.
+func MetricsPort() int {
+	env := os.Getenv("METRICS_PORT")
+	if env == "" {
+		return DefaultMetricsPort
+	}
+	port, err := strconv.Atoi(env)
+	if err != nil {
+		zap.L().Fatal("Metrics port env could not cast to int", zap.Error(err), zap.String("env", env))
+	}
+	return port
+}
+
+func HealthCheckTimeout() time.Duration {
+	env := os.Getenv("HEALTH_CHECK_TIMEOUT")
+	if env == "" {
+		return DefaultHealthCheckTimeout
+	}
+
+	timeout, err := strconv.Atoi(env)
+	if err != nil {
+		zap.L().Fatal("Health check timeout env could not cast to int", zap.Error(err), zap.String("env", env))
+	}
+	return time.Duration(timeout) * time.Second
+}
@@ -0,0 +1,31 @@
+package environment
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/h4yfans/case-study/common/breached"
+	"go.uber.org/zap"
+)
+
+const DefaultBreachedMinCount = 1
+
+func Breached() breached.Config {
+	return breached.Config{
+		Path:     os.Getenv("BREACHED_PASSWORDS_PATH"),
+		MinCount: getBreachedMinCount(),
+	}
+}
+
+func getBreachedMinCount() int {
+	env := os.Getenv("BREACHED_PASSWORDS_MIN_COUNT")
+	if env == "" {
+		return DefaultBreachedMinCount
+	}
+
+	count, err := strconv.Atoi(env)
+	if err != nil {
+		zap.L().Fatal("Breached passwords min count env could not cast to int", zap.Error(err), zap.String("env", env))
+	}
+	return count
+}
@@ -0,0 +1,14 @@
+package environment
+
+import (
+	"os"
+	"strings"
+
+	"github.com/h4yfans/case-study/common/emailaddr"
+)
+
+func EmailAddress() emailaddr.Config {
+	return emailaddr.Config{
+		FoldLocalPart: strings.ToUpper(os.Getenv("EMAIL_FOLD_LOCAL_PART")) == "TRUE",
+	}
+}
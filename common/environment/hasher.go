@@ -0,0 +1,94 @@
+package environment
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/h4yfans/case-study/common/hasher"
+	"go.uber.org/zap"
+)
+
+// Argon2id defaults follow the second recommended option of RFC 9106.
+const (
+	DefaultPasswordHashAlgorithm = hasher.Bcrypt
+	DefaultBcryptCost            = 12
+	DefaultArgon2Memory          = 64 * 1024 // 64 MiB
+	DefaultArgon2Iterations      = 3
+	DefaultArgon2Parallelism     = 4
+	DefaultArgon2SaltLength      = 16
+	DefaultArgon2KeyLength       = 32
+)
+
+func Hasher() hasher.Config {
+	return hasher.Config{
+		Algorithm:  getPasswordHashAlgorithm(),
+		BcryptCost: getBcryptCost(),
+		Argon2: hasher.Argon2Params{
+			Memory:      getArgon2Memory(),
+			Iterations:  getArgon2Iterations(),
+			Parallelism: getArgon2Parallelism(),
+			SaltLength:  DefaultArgon2SaltLength,
+			KeyLength:   DefaultArgon2KeyLength,
+		},
+	}
+}
+
+func getPasswordHashAlgorithm() string {
+	if algorithm := strings.ToUpper(os.Getenv("PASSWORD_HASH_ALGORITHM")); algorithm != "" {
+		return algorithm
+	}
+	return DefaultPasswordHashAlgorithm
+}
+
+func getBcryptCost() int {
+	env := os.Getenv("BCRYPT_COST")
+	if env == "" {
+		return DefaultBcryptCost
+	}
+
+	cost, err := strconv.Atoi(env)
+	if err != nil {
+		zap.L().Fatal("Bcrypt cost env could not cast to int", zap.Error(err), zap.String("env", env))
+	}
+	return cost
+}
+
+func getArgon2Memory() uint32 {
+	env := os.Getenv("ARGON2_MEMORY")
+	if env == "" {
+		return DefaultArgon2Memory
+	}
+
+	memory, err := strconv.ParseUint(env, 10, 32)
+	if err != nil {
+		zap.L().Fatal("Argon2 memory env could not cast to uint32", zap.Error(err), zap.String("env", env))
+	}
+	return uint32(memory)
+}
+
+func getArgon2Iterations() uint32 {
+	env := os.Getenv("ARGON2_ITERATIONS")
+	if env == "" {
+		return DefaultArgon2Iterations
+	}
+
+	iterations, err := strconv.ParseUint(env, 10, 32)
+	if err != nil {
+		zap.L().Fatal("Argon2 iterations env could not cast to uint32", zap.Error(err), zap.String("env", env))
+	}
+	return uint32(iterations)
+}
+
+func getArgon2Parallelism() uint8 {
+	env := os.Getenv("ARGON2_PARALLELISM")
+	if env == "" {
+		return DefaultArgon2Parallelism
+	}
+
+	parallelism, err := strconv.ParseUint(env, 10, 8)
+	if err != nil {
+		zap.L().Fatal("Argon2 parallelism env could not cast to uint8", zap.Error(err), zap.String("env", env))
+	}
+	return uint8(parallelism)
+}
@@ -0,0 +1,78 @@
+package environment
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/h4yfans/case-study/common/lockout"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultLockoutThreshold   = 5
+	DefaultLockoutIPThreshold = 0                // Disabled
+	DefaultLockoutDuration    = time.Minute * 15 // 15 Minute
+	DefaultLoginBackoffBase   = time.Second      // 1 Second
+)
+
+func Lockout() lockout.Config {
+	return lockout.Config{
+		Threshold:   getLockoutThreshold(),
+		IPThreshold: getLockoutIPThreshold(),
+		Duration:    getLockoutDuration(),
+		BackoffBase: getLoginBackoffBase(),
+	}
+}
+
+func getLockoutThreshold() int {
+	env := os.Getenv("LOCKOUT_THRESHOLD")
+	if env == "" {
+		return DefaultLockoutThreshold
+	}
+
+	threshold, err := strconv.Atoi(env)
+	if err != nil {
+		zap.L().Fatal("Lockout threshold env could not cast to int", zap.Error(err), zap.String("env", env))
+	}
+	return threshold
+}
+
+func getLockoutIPThreshold() int {
+	env := os.Getenv("LOCKOUT_IP_THRESHOLD")
+	if env == "" {
+		return DefaultLockoutIPThreshold
+	}
+
+	threshold, err := strconv.Atoi(env)
+	if err != nil {
+		zap.L().Fatal("Lockout ip threshold env could not cast to int", zap.Error(err), zap.String("env", env))
+	}
+	return threshold
+}
+
+func getLockoutDuration() time.Duration {
+	env := os.Getenv("LOCKOUT_DURATION")
+	if env == "" {
+		return DefaultLockoutDuration
+	}
+
+	duration, err := strconv.Atoi(env)
+	if err != nil {
+		zap.L().Fatal("Lockout duration env could not cast to int", zap.Error(err), zap.String("env", env))
+	}
+	return time.Duration(duration) * time.Second
+}
+
+func getLoginBackoffBase() time.Duration {
+	env := os.Getenv("LOGIN_BACKOFF_BASE")
+	if env == "" {
+		return DefaultLoginBackoffBase
+	}
+
+	base, err := strconv.Atoi(env)
+	if err != nil {
+		zap.L().Fatal("Login backoff base env could not cast to int", zap.Error(err), zap.String("env", env))
+	}
+	return time.Duration(base) * time.Second
+}
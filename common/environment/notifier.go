@@ -0,0 +1,56 @@
+package environment
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/h4yfans/case-study/common/notifier"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultNotifierFilePath = "notifications.log"
+	DefaultSMTPPort         = 587
+)
+
+func Notifier() notifier.Config {
+	return notifier.Config{
+		Driver:   getNotifierDriver(),
+		FilePath: getNotifierFilePath(),
+		SMTP: notifier.SMTPConfig{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     getSMTPPort(),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("SMTP_FROM"),
+		},
+		Local: Environment() == EnvironmentLocal,
+	}
+}
+
+// getNotifierDriver has no default: a deployment must pick how
+// notifications, which carry live tokens, are delivered.
+func getNotifierDriver() string {
+	return strings.ToUpper(os.Getenv("NOTIFIER_DRIVER"))
+}
+
+func getNotifierFilePath() string {
+	if path := os.Getenv("NOTIFIER_FILE_PATH"); path != "" {
+		return path
+	}
+	return DefaultNotifierFilePath
+}
+
+func getSMTPPort() int {
+	env := os.Getenv("SMTP_PORT")
+	if env == "" {
+		return DefaultSMTPPort
+	}
+
+	port, err := strconv.Atoi(env)
+	if err != nil {
+		zap.L().Fatal("SMTP port env could not cast to int", zap.Error(err), zap.String("env", env))
+	}
+	return port
+}
@@ -0,0 +1,52 @@
+package environment
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/h4yfans/case-study/common/password"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultPasswordMinLength = 8
+	DefaultPasswordMaxLength = password.MaxBytes
+)
+
+func Password() password.Config {
+	return password.Config{
+		MinLength:     getPasswordMinLength(),
+		MaxLength:     getPasswordMaxLength(),
+		RequireUpper:  strings.ToUpper(os.Getenv("PASSWORD_REQUIRE_UPPER")) == "TRUE",
+		RequireLower:  strings.ToUpper(os.Getenv("PASSWORD_REQUIRE_LOWER")) == "TRUE",
+		RequireDigit:  strings.ToUpper(os.Getenv("PASSWORD_REQUIRE_DIGIT")) == "TRUE",
+		RequireSymbol: strings.ToUpper(os.Getenv("PASSWORD_REQUIRE_SYMBOL")) == "TRUE",
+	}
+}
+
+func getPasswordMinLength() int {
+	env := os.Getenv("PASSWORD_MIN_LENGTH")
+	if env == "" {
+		return DefaultPasswordMinLength
+	}
+
+	length, err := strconv.Atoi(env)
+	if err != nil {
+		zap.L().Fatal("Password min length env could not cast to int", zap.Error(err), zap.String("env", env))
+	}
+	return length
+}
+
+func getPasswordMaxLength() int {
+	env := os.Getenv("PASSWORD_MAX_LENGTH")
+	if env == "" {
+		return DefaultPasswordMaxLength
+	}
+
+	length, err := strconv.Atoi(env)
+	if err != nil {
+		zap.L().Fatal("Password max length env could not cast to int", zap.Error(err), zap.String("env", env))
+	}
+	return length
+}
@@ -0,0 +1,48 @@
+package environment
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/h4yfans/case-study/common/server"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultShutdownDelay   = time.Duration(0)
+	DefaultShutdownTimeout = time.Second * 20 // 20 Seconds
+)
+
+func Server() server.Config {
+	return server.Config{
+		ShutdownDelay:   getShutdownDelay(),
+		ShutdownTimeout: getShutdownTimeout(),
+	}
+}
+
+func getShutdownDelay() time.Duration {
+	env := os.Getenv("SHUTDOWN_DELAY")
+	if env == "" {
+		return DefaultShutdownDelay
+	}
+
+	delay, err := strconv.Atoi(env)
+	if err != nil {
+		zap.L().Fatal("Shutdown delay env could not cast to int", zap.Error(err), zap.String("env", env))
+	}
+	return time.Duration(delay) * time.Second
+}
+
+func getShutdownTimeout() time.Duration {
+	env := os.Getenv("SHUTDOWN_TIMEOUT")
+	if env == "" {
+		return DefaultShutdownTimeout
+	}
+
+	timeout, err := strconv.Atoi(env)
+	if err != nil {
+		zap.L().Fatal("Shutdown timeout env could not cast to int", zap.Error(err), zap.String("env", env))
+	}
+	return time.Duration(timeout) * time.Second
+}
@@ -0,0 +1,100 @@
+package environment
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/h4yfans/case-study/common/token"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultJWTAlgorithm     = token.HS256
+	DefaultJWTIssuer        = "case-study"
+	DefaultAccessTokenTTL   = time.Minute * 15    // 15 Minute
+	DefaultRefreshTokenTTL  = time.Hour * 24 * 30 // 30 Day
+	DefaultPasswordResetTTL = time.Hour           // 1 Hour
+	DefaultVerificationTTL  = time.Hour * 24      // 1 Day
+)
+
+func Token() token.Config {
+	return token.Config{
+		Algorithm:        getJWTAlgorithm(),
+		Secret:           os.Getenv("JWT_SECRET"),
+		PrivateKeyPath:   os.Getenv("JWT_PRIVATE_KEY_PATH"),
+		PublicKeyPath:    os.Getenv("JWT_PUBLIC_KEY_PATH"),
+		Issuer:           getJWTIssuer(),
+		AccessTokenTTL:   getAccessTokenTTL(),
+		RefreshTokenTTL:  getRefreshTokenTTL(),
+		PasswordResetTTL: getPasswordResetTTL(),
+		VerificationTTL:  getVerificationTTL(),
+	}
+}
+
+func getJWTAlgorithm() string {
+	if algorithm := strings.ToUpper(os.Getenv("JWT_ALGORITHM")); algorithm != "" {
+		return algorithm
+	}
+	return DefaultJWTAlgorithm
+}
+
+func getJWTIssuer() string {
+	if issuer := os.Getenv("JWT_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return DefaultJWTIssuer
+}
+
+func getAccessTokenTTL() time.Duration {
+	env := os.Getenv("ACCESS_TOKEN_TTL")
+	if env == "" {
+		return DefaultAccessTokenTTL
+	}
+
+	ttl, err := strconv.Atoi(env)
+	if err != nil {
+		zap.L().Fatal("Access token ttl env could not cast to int", zap.Error(err), zap.String("env", env))
+	}
+	return time.Duration(ttl) * time.Second
+}
+
+func getRefreshTokenTTL() time.Duration {
+	env := os.Getenv("REFRESH_TOKEN_TTL")
+	if env == "" {
+		return DefaultRefreshTokenTTL
+	}
+
+	ttl, err := strconv.Atoi(env)
+	if err != nil {
+		zap.L().Fatal("Refresh token ttl env could not cast to int", zap.Error(err), zap.String("env", env))
+	}
+	return time.Duration(ttl) * time.Second
+}
+
+func getPasswordResetTTL() time.Duration {
+	env := os.Getenv("PASSWORD_RESET_TTL")
+	if env == "" {
+		return DefaultPasswordResetTTL
+	}
+
+	ttl, err := strconv.Atoi(env)
+	if err != nil {
+		zap.L().Fatal("Password reset ttl env could not cast to int", zap.Error(err), zap.String("env", env))
+	}
+	return time.Duration(ttl) * time.Second
+}
+
+func getVerificationTTL() time.Duration {
+	env := os.Getenv("EMAIL_VERIFICATION_TTL")
+	if env == "" {
+		return DefaultVerificationTTL
+	}
+
+	ttl, err := strconv.Atoi(env)
+	if err != nil {
+		zap.L().Fatal("Email verification ttl env could not cast to int", zap.Error(err), zap.String("env", env))
+	}
+	return time.Duration(ttl) * time.Second
+}
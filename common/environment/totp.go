@@ -0,0 +1,43 @@
+package environment
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/h4yfans/case-study/common/totp"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultTOTPIssuer   = "case-study"
+	DefaultChallengeTTL = time.Minute * 5 // 5 Minute
+)
+
+func TOTP() totp.Config {
+	return totp.Config{
+		Issuer:        getTOTPIssuer(),
+		EncryptionKey: os.Getenv("TOTP_ENCRYPTION_KEY"),
+		ChallengeTTL:  getChallengeTTL(),
+	}
+}
+
+func getTOTPIssuer() string {
+	if issuer := os.Getenv("TOTP_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return DefaultTOTPIssuer
+}
+
+func getChallengeTTL() time.Duration {
+	env := os.Getenv("TWO_FACTOR_CHALLENGE_TTL")
+	if env == "" {
+		return DefaultChallengeTTL
+	}
+
+	ttl, err := strconv.Atoi(env)
+	if err != nil {
+		zap.L().Fatal("Two factor challenge ttl env could not cast to int", zap.Error(err), zap.String("env", env))
+	}
+	return time.Duration(ttl) * time.Second
+}
@@ -0,0 +1,61 @@
+package environment
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/h4yfans/case-study/common/tracing"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultTracingServiceName  = "case-study"
+	DefaultTracingExporter     = tracing.ExporterNone
+	DefaultTracingOTLPEndpoint = "localhost:4318"
+	DefaultTracingSampleRatio  = 1.0
+)
+
+func Tracing() tracing.Config {
+	return tracing.Config{
+		ServiceName: getTracingServiceName(),
+		Exporter:    getTracingExporter(),
+		Endpoint:    getTracingOTLPEndpoint(),
+		Insecure:    strings.ToUpper(os.Getenv("TRACING_OTLP_INSECURE")) == "TRUE",
+		SampleRatio: getTracingSampleRatio(),
+	}
+}
+
+func getTracingServiceName() string {
+	if name := os.Getenv("TRACING_SERVICE_NAME"); name != "" {
+		return name
+	}
+	return DefaultTracingServiceName
+}
+
+func getTracingExporter() string {
+	if exporter := strings.ToUpper(os.Getenv("TRACING_EXPORTER")); exporter != "" {
+		return exporter
+	}
+	return DefaultTracingExporter
+}
+
+func getTracingOTLPEndpoint() string {
+	if endpoint := os.Getenv("TRACING_OTLP_ENDPOINT"); endpoint != "" {
+		return endpoint
+	}
+	return DefaultTracingOTLPEndpoint
+}
+
+func getTracingSampleRatio() float64 {
+	env := os.Getenv("TRACING_SAMPLE_RATIO")
+	if env == "" {
+		return DefaultTracingSampleRatio
+	}
+
+	ratio, err := strconv.ParseFloat(env, 64)
+	if err != nil || ratio < 0 || ratio > 1 {
+		zap.L().Fatal("Tracing sample ratio env must be a number between 0 and 1", zap.Error(err), zap.String("env", env))
+	}
+	return ratio
+}
@@ -0,0 +1,50 @@
+package etag
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FromTime returns a strong entity tag for a record last modified at t.
+// Postgres stores timestamps with microsecond precision, so that is the
+// precision the tag carries.
+func FromTime(t time.Time) string {
+	return `"` + strconv.FormatInt(t.UnixNano()/int64(time.Microsecond), 36) + `"`
+}
+
+// Match reports whether an If-Match header lists tag, using the strong
+// comparison RFC 7232 requires there: weak tags never match.
+func Match(header string, tag string) bool {
+	return match(header, tag, false)
+}
+
+// MatchWeak reports whether an If-None-Match header lists tag, ignoring the
+// weak indicator on either side.
+func MatchWeak(header string, tag string) bool {
+	return match(header, tag, true)
+}
+
+func match(header string, tag string, weak bool) bool {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return false
+	}
+	if header == "*" {
+		return true
+	}
+
+	if weak {
+		tag = strings.TrimPrefix(tag, "W/")
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package etag
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromTime(t *testing.T) {
+	at := time.Date(2021, 9, 1, 12, 0, 0, 123456789, time.UTC)
+
+	assert.Equal(t, FromTime(at), FromTime(at.Truncate(time.Microsecond)))
+	assert.NotEqual(t, FromTime(at), FromTime(at.Add(time.Microsecond)))
+}
+
+func TestMatch(t *testing.T) {
+	tag := `"abc"`
+
+	assert.True(t, Match(`"abc"`, tag))
+	assert.True(t, Match(`"xyz", "abc"`, tag))
+	assert.True(t, Match("*", tag))
+	assert.False(t, Match("", tag))
+	assert.False(t, Match(`"xyz"`, tag))
+	assert.False(t, Match(`W/"abc"`, tag))
+}
+
+func TestMatchWeak(t *testing.T) {
+	tag := `"abc"`
+
+	assert.True(t, MatchWeak(`W/"abc"`, tag))
+	assert.True(t, MatchWeak(`"xyz", W/"abc"`, tag))
+	assert.False(t, MatchWeak(`"xyz"`, tag))
+}
@@ -0,0 +1,177 @@
+package hasher
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/h4yfans/case-study/common/metrics"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	Bcrypt   = "BCRYPT"
+	Argon2id = "ARGON2ID"
+)
+
+const argon2idPrefix = "$argon2id$"
+
+var (
+	ErrUnknownHash = errors.New("unknown password hash format")
+	ErrInvalidHash = errors.New("invalid password hash")
+)
+
+type Config struct {
+	Algorithm  string
+	BcryptCost int
+	Argon2     Argon2Params
+}
+
+type Argon2Params struct {
+	Memory      uint32 // KiB
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// Hasher hashes new passwords with the configured algorithm and verifies
+// hashes of any supported algorithm. The algorithm and its parameters are
+// read from the stored hash itself, so changing the config never breaks
+// existing passwords.
+type Hasher struct {
+	config Config
+}
+
+// New builds a hasher from the given config. An unusable config stops the
+// service at startup.
+func New(config Config) *Hasher {
+	switch config.Algorithm {
+	case Bcrypt:
+		if config.BcryptCost < bcrypt.MinCost || config.BcryptCost > bcrypt.MaxCost {
+			zap.L().Fatal("Bcrypt cost is out of range", zap.Int("cost", config.BcryptCost))
+		}
+	case Argon2id:
+		params := config.Argon2
+		if params.Memory == 0 || params.Iterations == 0 || params.Parallelism == 0 || params.SaltLength == 0 || params.KeyLength == 0 {
+			zap.L().Fatal("Argon2id parameters must be positive", zap.Any("params", params))
+		}
+	default:
+		zap.L().Fatal("Unknown password hash algorithm", zap.String("algorithm", config.Algorithm))
+	}
+
+	return &Hasher{
+		config: config,
+	}
+}
+
+func (h *Hasher) HashPassword(password string) (string, error) {
+	defer metrics.ObservePasswordHash(strings.ToLower(h.config.Algorithm), "hash", time.Now())
+
+	if h.config.Algorithm == Argon2id {
+		return h.hashArgon2id(password)
+	}
+
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), h.config.BcryptCost)
+	return string(bytes), err
+}
+
+// VerifyPassword reports whether password matches hash and, if it does,
+// whether the hash should be replaced because it was made with another
+// algorithm or outdated parameters.
+func (h *Hasher) VerifyPassword(hash string, password string) (bool, bool, error) {
+	switch {
+	case strings.HasPrefix(hash, argon2idPrefix):
+		defer metrics.ObservePasswordHash(strings.ToLower(Argon2id), "verify", time.Now())
+		return h.verifyArgon2id(hash, password)
+	case strings.HasPrefix(hash, "$2"):
+		defer metrics.ObservePasswordHash(strings.ToLower(Bcrypt), "verify", time.Now())
+		return h.verifyBcrypt(hash, password)
+	default:
+		return false, false, ErrUnknownHash
+	}
+}
+
+func (h *Hasher) verifyBcrypt(hash string, password string) (bool, bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if err == bcrypt.ErrMismatchedHashAndPassword {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+
+	cost, err := bcrypt.Cost([]byte(hash))
+	if err != nil {
+		return false, false, err
+	}
+
+	return true, h.config.Algorithm != Bcrypt || cost != h.config.BcryptCost, nil
+}
+
+func (h *Hasher) hashArgon2id(password string) (string, error) {
+	params := h.config.Argon2
+
+	salt := make([]byte, params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+
+	// PHC string format, the same one used by the reference implementation.
+	return fmt.Sprintf(
+		"%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2idPrefix,
+		argon2.Version,
+		params.Memory,
+		params.Iterations,
+		params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (h *Hasher) verifyArgon2id(hash string, password string) (bool, bool, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return false, false, ErrInvalidHash
+	}
+
+	var version int
+	_, err := fmt.Sscanf(parts[2], "v=%d", &version)
+	if err != nil || version != argon2.Version {
+		return false, false, ErrInvalidHash
+	}
+
+	var params Argon2Params
+	_, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism)
+	if err != nil {
+		return false, false, ErrInvalidHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, false, ErrInvalidHash
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return false, false, ErrInvalidHash
+	}
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+
+	candidate := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	if subtle.ConstantTimeCompare(key, candidate) != 1 {
+		return false, false, nil
+	}
+
+	return true, h.config.Algorithm != Argon2id || params != h.config.Argon2, nil
+}
@@ -0,0 +1,78 @@
+package hasher
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var argon2Params = Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func TestBcrypt(t *testing.T) {
+	h := New(Config{Algorithm: Bcrypt, BcryptCost: bcrypt.MinCost})
+
+	hash, err := h.HashPassword("secret")
+	assert.NoError(t, err)
+
+	ok, rehash, err := h.VerifyPassword(hash, "secret")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.False(t, rehash)
+
+	ok, _, err = h.VerifyPassword(hash, "wrong")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	stronger := New(Config{Algorithm: Bcrypt, BcryptCost: bcrypt.MinCost + 1})
+	ok, rehash, err = stronger.VerifyPassword(hash, "secret")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, rehash, "cost changed")
+}
+
+func TestArgon2id(t *testing.T) {
+	h := New(Config{Algorithm: Argon2id, Argon2: argon2Params})
+
+	hash, err := h.HashPassword("secret")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"))
+
+	ok, rehash, err := h.VerifyPassword(hash, "secret")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.False(t, rehash)
+
+	ok, _, err = h.VerifyPassword(hash, "wrong")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	params := argon2Params
+	params.Iterations = 2
+	ok, rehash, err = New(Config{Algorithm: Argon2id, Argon2: params}).VerifyPassword(hash, "secret")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, rehash, "parameters changed")
+}
+
+func TestMigrateAlgorithm(t *testing.T) {
+	bcryptHash, err := New(Config{Algorithm: Bcrypt, BcryptCost: bcrypt.MinCost}).HashPassword("secret")
+	assert.NoError(t, err)
+
+	h := New(Config{Algorithm: Argon2id, Argon2: argon2Params})
+	ok, rehash, err := h.VerifyPassword(bcryptHash, "secret")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, rehash)
+}
+
+func TestVerifyInvalidHash(t *testing.T) {
+	h := New(Config{Algorithm: Argon2id, Argon2: argon2Params})
+
+	_, _, err := h.VerifyPassword("plain", "secret")
+	assert.Equal(t, ErrUnknownHash, err)
+
+	_, _, err = h.VerifyPassword("$argon2id$v=19$m=1024,t=1,p=1$salt", "secret")
+	assert.Equal(t, ErrInvalidHash, err)
+}
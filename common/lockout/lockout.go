@@ -0,0 +1,85 @@
+package lockout
+
+import (
+	"strings"
+	"time"
+)
+
+type Config struct {
+	Threshold   int
+	IPThreshold int
+	Duration    time.Duration
+	BackoffBase time.Duration
+}
+
+// Policy decides how long a login key is blocked after consecutive failures.
+// Each failure doubles the delay until the threshold is reached, which locks
+// the key for the full duration.
+type Policy struct {
+	threshold   int
+	ipThreshold int
+	duration    time.Duration
+	backoffBase time.Duration
+}
+
+func NewPolicy(config Config) *Policy {
+	return &Policy{
+		threshold:   config.Threshold,
+		ipThreshold: config.IPThreshold,
+		duration:    config.Duration,
+		backoffBase: config.BackoffBase,
+	}
+}
+
+// Window is how long failures are remembered. A failure after a quiet window
+// starts counting from one again.
+func (p *Policy) Window() time.Duration {
+	return p.duration
+}
+
+// Account returns the block for an account after its nth failure and whether
+// the account is now locked.
+func (p *Policy) Account(failures int) (time.Duration, bool) {
+	return p.block(failures, p.threshold)
+}
+
+// TracksIP tells whether failures are counted per source address too. It is
+// off unless an IP threshold is configured: behind a proxy or load balancer
+// every client shares one address and would be blocked together.
+func (p *Policy) TracksIP() bool {
+	return p.ipThreshold > 0
+}
+
+// IP returns the block for a source address after its nth failure.
+func (p *Policy) IP(failures int) time.Duration {
+	delay, _ := p.block(failures, p.ipThreshold)
+	return delay
+}
+
+func (p *Policy) block(failures int, threshold int) (time.Duration, bool) {
+	if failures <= 0 {
+		return 0, false
+	}
+	if failures >= threshold {
+		return p.duration, true
+	}
+
+	delay := p.backoffBase
+	for i := 1; i < failures && delay < p.duration; i++ {
+		delay *= 2
+	}
+	if delay > p.duration {
+		delay = p.duration
+	}
+	return delay, false
+}
+
+// EmailKey identifies the attempts made against an email address. Unknown
+// addresses are tracked as well so a block does not reveal which exist.
+func EmailKey(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func IPKey(ip string) string {
+	return "ip:" + ip
+}
@@ -0,0 +1,40 @@
+package lockout
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccount(t *testing.T) {
+	p := NewPolicy(Config{Threshold: 5, IPThreshold: 20, Duration: time.Minute * 15, BackoffBase: time.Second})
+
+	expected := []time.Duration{0, time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second}
+	for failures, delay := range expected {
+		got, locked := p.Account(failures)
+		assert.Equal(t, delay, got, "failures %d", failures)
+		assert.False(t, locked)
+	}
+
+	delay, locked := p.Account(5)
+	assert.Equal(t, time.Minute*15, delay)
+	assert.True(t, locked)
+}
+
+func TestIP(t *testing.T) {
+	p := NewPolicy(Config{Threshold: 5, IPThreshold: 20, Duration: time.Minute, BackoffBase: time.Second})
+
+	assert.Equal(t, 32*time.Second, p.IP(6))
+	assert.Equal(t, time.Minute, p.IP(7), "backoff is capped at the lockout duration")
+	assert.Equal(t, time.Minute, p.IP(20))
+}
+
+func TestTracksIP(t *testing.T) {
+	assert.True(t, NewPolicy(Config{Threshold: 5, IPThreshold: 20}).TracksIP())
+	assert.False(t, NewPolicy(Config{Threshold: 5}).TracksIP())
+}
+
+func TestEmailKey(t *testing.T) {
+	assert.Equal(t, "email:kaan@test.com", EmailKey(" Kaan@Test.com "))
+}
@@ -2,6 +2,7 @@ package logging
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/getsentry/sentry-go"
 	"github.com/h4yfans/case-study/common/environment"
@@ -10,6 +11,8 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
+const sentryFlushTimeout = time.Second * 2 // 2 Seconds
+
 func Initialize() {
 	var cfg zap.Config
 	if environment.Debug() {
@@ -29,6 +32,8 @@ func Initialize() {
 	zap.ReplaceGlobals(log)
 }
 
+// Close sends the events still queued for Sentry and flushes buffered log
+// entries.
 func Close() {
 	defer func() {
 		_ = zap.L().Sync()
@@ -36,6 +41,7 @@ func Close() {
 	defer func() {
 		sentry.Recover()
 	}()
+	sentry.Flush(sentryFlushTimeout)
 }
 
 func getLogLevel(level string) zapcore.Level {
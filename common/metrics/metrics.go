@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/h4yfans/case-study/common"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// unmatchedRoute labels requests no route template matched, so clients
+// probing random paths cannot blow up the label cardinality.
+const unmatchedRoute = "unmatched"
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests handled, by method, route template and status code.",
+	}, []string{"method", "route", "code"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Time taken to handle HTTP requests, by method, route template and status code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "code"})
+
+	operationDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "usecase_operation_duration_seconds",
+		Help:    "Time taken by usecase operations, by usecase, operation and result.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"usecase", "operation", "result"})
+
+	passwordHashDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "password_hash_duration_seconds",
+		Help: "Time taken to hash and verify passwords, by algorithm and operation.",
+		// Hashing is slow on purpose, well beyond the default buckets.
+		Buckets: prometheus.ExponentialBuckets(0.005, 2, 12),
+	}, []string{"algorithm", "operation"})
+)
+
+// Handler serves the metrics in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// RegisterDB exposes the connection pool statistics of db, labelled with the
+// database name.
+func RegisterDB(db *sql.DB, name string) {
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// Middleware counts and times requests per route template of router, under
+// the unmatched label when no route matches.
+func Middleware(router *mux.Router) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			recorder := &statusRecorder{ResponseWriter: w, code: http.StatusOK}
+			next.ServeHTTP(recorder, r)
+
+			route := common.RouteTemplate(router, r)
+			if route == "" {
+				route = unmatchedRoute
+			}
+
+			labels := prometheus.Labels{"method": r.Method, "route": route, "code": strconv.Itoa(recorder.code)}
+			httpRequests.With(labels).Inc()
+			httpDuration.With(labels).Observe(time.Since(start).Seconds())
+		})
+	}
+}
+
+// ObserveOperation records how long a usecase operation that started at
+// start took and whether it failed.
+func ObserveOperation(usecase string, operation string, start time.Time, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	operationDuration.WithLabelValues(usecase, operation, result).Observe(time.Since(start).Seconds())
+}
+
+// ObservePasswordHash records how long hashing or verifying a password with
+// algorithm took.
+func ObservePasswordHash(algorithm string, operation string, start time.Time) {
+	passwordHashDuration.WithLabelValues(algorithm, operation).Observe(time.Since(start).Seconds())
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	s.code = code
+	s.ResponseWriter.WriteHeader(code)
+}
@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleCount(t *testing.T, histogram *prometheus.HistogramVec, labels ...string) uint64 {
+	var metric dto.Metric
+	require.NoError(t, histogram.WithLabelValues(labels...).(prometheus.Histogram).Write(&metric))
+	return metric.GetHistogram().GetSampleCount()
+}
+
+func newMeasuredRouter() http.Handler {
+	router := mux.NewRouter()
+	router.HandleFunc("/things/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}).Methods(http.MethodGet)
+	return Middleware(router)(router)
+}
+
+func TestMiddleware(t *testing.T) {
+	handler := newMeasuredRouter()
+
+	for _, path := range []string{"/things/1", "/things/2"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(httpRequests.WithLabelValues(http.MethodGet, "/things/{id}", "418")))
+	assert.Equal(t, uint64(2), sampleCount(t, httpDuration, http.MethodGet, "/things/{id}", "418"))
+}
+
+func TestMiddlewareCountsUnmatched(t *testing.T) {
+	handler := newMeasuredRouter()
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/things/1", nil))
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(httpRequests.WithLabelValues(http.MethodGet, unmatchedRoute, "404")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(httpRequests.WithLabelValues(http.MethodPost, unmatchedRoute, "405")))
+}
+
+func TestMiddlewareDefaultsToOK(t *testing.T) {
+	handler := Middleware(mux.NewRouter())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/anything", nil))
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(httpRequests.WithLabelValues(http.MethodPost, unmatchedRoute, "200")))
+}
+
+func TestObserveOperation(t *testing.T) {
+	ObserveOperation("test", "Do", time.Now(), nil)
+	ObserveOperation("test", "Do", time.Now(), errors.New("failed"))
+	ObserveOperation("test", "Do", time.Now(), errors.New("failed"))
+
+	assert.Equal(t, uint64(1), sampleCount(t, operationDuration, "test", "Do", "ok"))
+	assert.Equal(t, uint64(2), sampleCount(t, operationDuration, "test", "Do", "error"))
+}
+
+func TestObservePasswordHash(t *testing.T) {
+	ObservePasswordHash("test", "hash", time.Now())
+
+	assert.Equal(t, uint64(1), sampleCount(t, passwordHashDuration, "test", "hash"))
+}
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/h4yfans/case-study/common"
+	"github.com/h4yfans/case-study/domain"
+)
+
+type contextKey string
+
+const principalKey contextKey = "principal"
+
+// Authenticator resolves the principal of a request. It returns nil, nil when
+// the request carries no credentials at all.
+type Authenticator interface {
+	Authenticate(r *http.Request) (*domain.Principal, error)
+}
+
+type BearerAuthenticator struct {
+	usecase domain.AuthUsecase
+}
+
+func NewBearerAuthenticator(usecase domain.AuthUsecase) *BearerAuthenticator {
+	return &BearerAuthenticator{
+		usecase: usecase,
+	}
+}
+
+func (b *BearerAuthenticator) Authenticate(r *http.Request) (*domain.Principal, error) {
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return nil, nil
+	}
+
+	parts := strings.SplitN(header, " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") || strings.TrimSpace(parts[1]) == "" {
+		return nil, common.InvalidToken
+	}
+
+	return b.usecase.Authenticate(r.Context(), strings.TrimSpace(parts[1]))
+}
+
+// Authentication is a mux middleware that authenticates every matched route.
+// Routes require a principal unless they were registered through Public.
+type Authentication struct {
+	authenticator Authenticator
+	public        map[*mux.Route]bool
+}
+
+func NewAuthentication(authenticator Authenticator) *Authentication {
+	return &Authentication{
+		authenticator: authenticator,
+		public:        make(map[*mux.Route]bool),
+	}
+}
+
+// Public marks the route as reachable without authentication.
+func (a *Authentication) Public(route *mux.Route) *mux.Route {
+	a.public[route] = true
+	return route
+}
+
+func (a *Authentication) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		public := a.public[mux.CurrentRoute(r)]
+
+		principal, err := a.authenticator.Authenticate(r)
+		if err != nil && !public {
+			if err != common.InvalidToken && err != common.Unauthorized {
+				// Failing to check the credentials does not make them wrong.
+				common.RespondWithJSON(w, common.GetStatusCode(err), common.ResponseError{Error: err.Error()})
+				return
+			}
+			unauthorized(w, err)
+			return
+		}
+		if principal == nil {
+			if !public {
+				unauthorized(w, common.Unauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		recordUser(r.Context(), principal.UserID)
+		next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+	})
+}
+
+func WithPrincipal(ctx context.Context, principal *domain.Principal) context.Context {
+	return context.WithValue(ctx, principalKey, principal)
+}
+
+func PrincipalFromContext(ctx context.Context) (*domain.Principal, bool) {
+	principal, ok := ctx.Value(principalKey).(*domain.Principal)
+	return principal, ok && principal != nil
+}
+
+func unauthorized(w http.ResponseWriter, err error) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="case-study"`)
+	common.RespondWithJSON(w, http.StatusUnauthorized, common.ResponseError{Error: err.Error()})
+}
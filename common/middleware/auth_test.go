@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/h4yfans/case-study/common"
+	"github.com/h4yfans/case-study/domain"
+	mocks "github.com/h4yfans/case-study/mocks/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newRouter(usecase domain.AuthUsecase) *mux.Router {
+	r := mux.NewRouter()
+	auth := NewAuthentication(NewBearerAuthenticator(usecase))
+	r.Use(auth.Middleware)
+
+	echo := func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := PrincipalFromContext(r.Context())
+		if ok {
+			common.RespondWithJSON(w, http.StatusOK, principal)
+			return
+		}
+		common.RespondWithJSON(w, http.StatusOK, nil)
+	}
+	auth.Public(r.HandleFunc("/public", echo).Methods(http.MethodGet))
+	r.HandleFunc("/private", echo).Methods(http.MethodGet)
+	return r
+}
+
+func TestAuthentication(t *testing.T) {
+	t.Run("should allow anonymous on public route", func(t *testing.T) {
+		mockUCase := new(mocks.AuthUsecase)
+		req := httptest.NewRequest(http.MethodGet, "/public", nil)
+
+		rec := httptest.NewRecorder()
+		newRouter(mockUCase).ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockUCase.AssertExpectations(t)
+	})
+
+	t.Run("should return 401 without token", func(t *testing.T) {
+		mockUCase := new(mocks.AuthUsecase)
+		req := httptest.NewRequest(http.MethodGet, "/private", nil)
+
+		rec := httptest.NewRecorder()
+		newRouter(mockUCase).ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.NotEmpty(t, rec.Header().Get("WWW-Authenticate"))
+		mockUCase.AssertExpectations(t)
+	})
+
+	t.Run("should return 401 with malformed header", func(t *testing.T) {
+		mockUCase := new(mocks.AuthUsecase)
+		req := httptest.NewRequest(http.MethodGet, "/private", nil)
+		req.Header.Set("Authorization", "Basic abc")
+
+		rec := httptest.NewRecorder()
+		newRouter(mockUCase).ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		mockUCase.AssertExpectations(t)
+	})
+
+	t.Run("should return 401 with invalid token", func(t *testing.T) {
+		mockUCase := new(mocks.AuthUsecase)
+		mockUCase.On("Authenticate", mock.Anything, "bad").Return(nil, common.InvalidToken)
+		req := httptest.NewRequest(http.MethodGet, "/private", nil)
+		req.Header.Set("Authorization", "Bearer bad")
+
+		rec := httptest.NewRecorder()
+		newRouter(mockUCase).ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		mockUCase.AssertExpectations(t)
+	})
+
+	t.Run("should not reject the token when it could not be checked", func(t *testing.T) {
+		mockUCase := new(mocks.AuthUsecase)
+		mockUCase.On("Authenticate", mock.Anything, "good").Return(nil, common.Timeout)
+		req := httptest.NewRequest(http.MethodGet, "/private", nil)
+		req.Header.Set("Authorization", "Bearer good")
+
+		rec := httptest.NewRecorder()
+		newRouter(mockUCase).ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Empty(t, rec.Header().Get("WWW-Authenticate"))
+		mockUCase.AssertExpectations(t)
+	})
+
+	t.Run("should pass principal to protected route", func(t *testing.T) {
+		mockUCase := new(mocks.AuthUsecase)
+		mockUCase.On("Authenticate", mock.Anything, "good").Return(&domain.Principal{UserID: 1}, nil)
+		req := httptest.NewRequest(http.MethodGet, "/private", nil)
+		req.Header.Set("Authorization", "Bearer good")
+
+		rec := httptest.NewRecorder()
+		newRouter(mockUCase).ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"UserID":1,"Role":"","Permissions":null}`, rec.Body.String())
+		mockUCase.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/h4yfans/case-study/common"
+	"github.com/h4yfans/case-study/common/logging"
+	"go.uber.org/zap"
+)
+
+const (
+	requestIDKey   contextKey = "request_id"
+	accessEntryKey contextKey = "access_entry"
+
+	maxRequestIDLength = 128
+)
+
+// RequestLogging tags every request with an ID and logs one access entry per
+// request once it is handled, naming the route of router it matched. The ID
+// is taken from the X-Request-ID header when the client or a proxy sent a
+// usable one and generated otherwise. It is echoed in the response header
+// and carried by the request-scoped logger that logging.FromContext returns.
+func RequestLogging(router *mux.Router) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			requestID := r.Header.Get(common.RequestIDHeader)
+			if !validRequestID(requestID) {
+				requestID = newRequestID()
+			}
+			w.Header().Set(common.RequestIDHeader, requestID)
+
+			// The trace and span IDs are added by logging.FromContext, the
+			// stored logger must not carry them already.
+			entry := &accessEntry{}
+			ctx := context.WithValue(r.Context(), requestIDKey, requestID)
+			ctx = context.WithValue(ctx, accessEntryKey, entry)
+			ctx = logging.WithLogger(ctx, zap.L().With(zap.String("request_id", requestID)))
+			logger := logging.FromContext(ctx)
+
+			recorder := &responseRecorder{ResponseWriter: w, code: http.StatusOK}
+			next.ServeHTTP(recorder, r.WithContext(ctx))
+
+			fields := []zap.Field{
+				zap.String("method", r.Method),
+				zap.String("route", common.RouteTemplate(router, r)),
+				zap.Int("status", recorder.code),
+				zap.Int("bytes", recorder.bytes),
+				zap.Duration("duration", time.Since(start)),
+			}
+			if entry.userID != 0 {
+				fields = append(fields, zap.Int("user_id", entry.userID))
+			}
+
+			if recorder.code >= http.StatusInternalServerError {
+				logger.Error("Request handled", fields...)
+				return
+			}
+			logger.Info("Request handled", fields...)
+		})
+	}
+}
+
+// RequestIDFromContext returns the ID RequestLogging gave the request.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	requestID, ok := ctx.Value(requestIDKey).(string)
+	return requestID, ok
+}
+
+// accessEntry collects what inner middleware learns about a request for its
+// access log entry.
+type accessEntry struct {
+	userID int
+}
+
+// recordUser notes the authenticated user for the access log, if the request
+// is being logged.
+func recordUser(ctx context.Context, userID int) {
+	if entry, ok := ctx.Value(accessEntryKey).(*accessEntry); ok {
+		entry.userID = userID
+	}
+}
+
+// validRequestID accepts IDs of letters, digits and -_.: only, so a client
+// cannot forge log lines or headers through it.
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range requestID {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	code  int
+	bytes int
+}
+
+func (r *responseRecorder) WriteHeader(code int) {
+	r.code = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
@@ -0,0 +1,167 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/h4yfans/case-study/common"
+	"github.com/h4yfans/case-study/common/logging"
+	"github.com/h4yfans/case-study/domain"
+	mocks "github.com/h4yfans/case-study/mocks/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func newLoggedRouter(usecase domain.AuthUsecase) http.Handler {
+	r := mux.NewRouter()
+	auth := NewAuthentication(NewBearerAuthenticator(usecase))
+	r.Use(auth.Middleware)
+
+	auth.Public(r.HandleFunc("/things/{id}", func(w http.ResponseWriter, r *http.Request) {
+		requestID, _ := RequestIDFromContext(r.Context())
+		logging.FromContext(r.Context()).Info("Handling", zap.String("seen", requestID))
+		common.RespondWithJSON(w, http.StatusNotFound, common.ResponseError{Error: common.UserNotExist.Error()})
+	}).Methods(http.MethodGet))
+	r.HandleFunc("/private", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("secret"))
+	}).Methods(http.MethodGet)
+	return RequestLogging(r)(r)
+}
+
+func observeLogs(t *testing.T) *observer.ObservedLogs {
+	core, logs := observer.New(zap.InfoLevel)
+	t.Cleanup(zap.ReplaceGlobals(zap.New(core)))
+	return logs
+}
+
+func TestRequestLogging(t *testing.T) {
+	t.Run("should generate a request id", func(t *testing.T) {
+		logs := observeLogs(t)
+		req := httptest.NewRequest(http.MethodGet, "/things/1", nil)
+
+		rec := httptest.NewRecorder()
+		newLoggedRouter(new(mocks.AuthUsecase)).ServeHTTP(rec, req)
+
+		requestID := rec.Header().Get(common.RequestIDHeader)
+		assert.Len(t, requestID, 32)
+
+		var body common.ResponseError
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, requestID, body.RequestID)
+
+		entries := logs.All()
+		require.Len(t, entries, 2)
+		assert.Equal(t, requestID, entries[0].ContextMap()["seen"])
+		assert.Equal(t, requestID, entries[0].ContextMap()["request_id"])
+
+		access := entries[1].ContextMap()
+		assert.Equal(t, "Request handled", entries[1].Message)
+		assert.Equal(t, requestID, access["request_id"])
+		assert.Equal(t, http.MethodGet, access["method"])
+		assert.Equal(t, "/things/{id}", access["route"])
+		assert.Equal(t, int64(http.StatusNotFound), access["status"])
+		assert.Equal(t, int64(rec.Body.Len()), access["bytes"])
+		assert.Contains(t, access, "duration")
+		assert.NotContains(t, access, "user_id")
+	})
+
+	t.Run("should keep a valid request id", func(t *testing.T) {
+		observeLogs(t)
+		req := httptest.NewRequest(http.MethodGet, "/things/1", nil)
+		req.Header.Set(common.RequestIDHeader, "lb-1234:abcd")
+
+		rec := httptest.NewRecorder()
+		newLoggedRouter(new(mocks.AuthUsecase)).ServeHTTP(rec, req)
+		assert.Equal(t, "lb-1234:abcd", rec.Header().Get(common.RequestIDHeader))
+	})
+
+	t.Run("should replace an unsafe request id", func(t *testing.T) {
+		observeLogs(t)
+		req := httptest.NewRequest(http.MethodGet, "/things/1", nil)
+		req.Header.Set(common.RequestIDHeader, "forged\nline")
+
+		rec := httptest.NewRecorder()
+		newLoggedRouter(new(mocks.AuthUsecase)).ServeHTTP(rec, req)
+		assert.Len(t, rec.Header().Get(common.RequestIDHeader), 32)
+	})
+
+	t.Run("should log the authenticated user", func(t *testing.T) {
+		logs := observeLogs(t)
+		req := httptest.NewRequest(http.MethodGet, "/private", nil)
+		req.Header.Set("Authorization", "Bearer valid")
+
+		mockUCase := new(mocks.AuthUsecase)
+		mockUCase.On("Authenticate", mock.Anything, "valid").Return(&domain.Principal{UserID: 7}, nil)
+
+		rec := httptest.NewRecorder()
+		newLoggedRouter(mockUCase).ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		entries := logs.All()
+		require.Len(t, entries, 1)
+		assert.Equal(t, int64(7), entries[0].ContextMap()["user_id"])
+		assert.Equal(t, int64(len("secret")), entries[0].ContextMap()["bytes"])
+		mockUCase.AssertExpectations(t)
+	})
+
+	t.Run("should log rejected requests", func(t *testing.T) {
+		logs := observeLogs(t)
+		req := httptest.NewRequest(http.MethodGet, "/private", nil)
+
+		rec := httptest.NewRecorder()
+		newLoggedRouter(new(mocks.AuthUsecase)).ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+		var body common.ResponseError
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, rec.Header().Get(common.RequestIDHeader), body.RequestID)
+
+		entries := logs.All()
+		require.Len(t, entries, 1)
+		assert.Equal(t, int64(http.StatusUnauthorized), entries[0].ContextMap()["status"])
+	})
+	t.Run("should log unmatched requests", func(t *testing.T) {
+		logs := observeLogs(t)
+
+		for _, req := range []*http.Request{
+			httptest.NewRequest(http.MethodGet, "/missing", nil),
+			httptest.NewRequest(http.MethodPost, "/things/1", nil),
+		} {
+			rec := httptest.NewRecorder()
+			newLoggedRouter(new(mocks.AuthUsecase)).ServeHTTP(rec, req)
+			assert.Len(t, rec.Header().Get(common.RequestIDHeader), 32)
+		}
+
+		entries := logs.All()
+		require.Len(t, entries, 2)
+		assert.Equal(t, int64(http.StatusNotFound), entries[0].ContextMap()["status"])
+		assert.Equal(t, "", entries[0].ContextMap()["route"])
+		assert.Equal(t, int64(http.StatusMethodNotAllowed), entries[1].ContextMap()["status"])
+	})
+	t.Run("should log the trace of the request", func(t *testing.T) {
+		logs := observeLogs(t)
+		spanContext := trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: trace.TraceID{1},
+			SpanID:  trace.SpanID{2},
+		})
+		req := httptest.NewRequest(http.MethodGet, "/things/1", nil)
+		req = req.WithContext(trace.ContextWithSpanContext(req.Context(), spanContext))
+
+		rec := httptest.NewRecorder()
+		newLoggedRouter(new(mocks.AuthUsecase)).ServeHTTP(rec, req)
+
+		entries := logs.All()
+		require.Len(t, entries, 2)
+		for _, entry := range entries {
+			assert.Equal(t, spanContext.TraceID().String(), entry.ContextMap()["trace_id"])
+			assert.Equal(t, spanContext.SpanID().String(), entry.ContextMap()["span_id"])
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/h4yfans/case-study/domain"
+	"go.uber.org/zap"
+)
+
+const (
+	DriverLog  = "LOG"
+	DriverFile = "FILE"
+	DriverSMTP = "SMTP"
+)
+
+type Config struct {
+	Driver   string
+	FilePath string
+	SMTP     SMTPConfig
+	// Local is set when running in local development, the only place the
+	// log and file drivers may be used.
+	Local bool
+}
+
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// New returns the notifier selected by the config. The log and file drivers
+// deliver nothing, so they are refused outside local development.
+func New(config Config) domain.Notifier {
+	switch config.Driver {
+	case "":
+		zap.L().Fatal("Notifier driver is not set")
+	case DriverLog, DriverFile:
+		if !config.Local {
+			zap.L().Fatal("Notifier driver is only allowed in local development", zap.String("driver", config.Driver))
+		}
+		if config.Driver == DriverLog {
+			return NewLogNotifier()
+		}
+		return NewFileNotifier(config.FilePath)
+	case DriverSMTP:
+		if config.SMTP.Host == "" || config.SMTP.From == "" {
+			zap.L().Fatal("SMTP notifier needs a host and a sender address")
+		}
+		return NewSMTPNotifier(config.SMTP)
+	default:
+		zap.L().Fatal("Unknown notifier driver", zap.String("driver", config.Driver))
+	}
+	return nil
+}
+
+// LogNotifier only records that a notification was sent. The body carries
+// live tokens, so it is never logged; use FileNotifier to read it.
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (l *LogNotifier) Send(ctx context.Context, notification *domain.Notification) error {
+	zap.L().Info(
+		"Notification",
+		zap.String("to", notification.To),
+		zap.String("subject", notification.Subject),
+	)
+	return nil
+}
+
+// FileNotifier appends every notification as a JSON line to a file.
+type FileNotifier struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileNotifier(path string) *FileNotifier {
+	return &FileNotifier{
+		path: path,
+	}
+}
+
+func (f *FileNotifier) Send(ctx context.Context, notification *domain.Notification) error {
+	line, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,115 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/h4yfans/case-study/domain"
+)
+
+var errHeaderInjection = errors.New("notification header contains a line break")
+
+// SMTPNotifier delivers notifications as plain text mail. It upgrades the
+// connection with STARTTLS whenever the server offers it, and only
+// authenticates when a username is configured.
+type SMTPNotifier struct {
+	address string
+	host    string
+	from    string
+	auth    smtp.Auth
+}
+
+func NewSMTPNotifier(config SMTPConfig) *SMTPNotifier {
+	s := &SMTPNotifier{
+		address: net.JoinHostPort(config.Host, fmt.Sprint(config.Port)),
+		host:    config.Host,
+		from:    config.From,
+	}
+	if config.Username != "" {
+		s.auth = smtp.PlainAuth("", config.Username, config.Password, config.Host)
+	}
+	return s
+}
+
+func (s *SMTPNotifier) Send(ctx context.Context, notification *domain.Notification) error {
+	if strings.ContainsAny(notification.To+notification.Subject, "\r\n") {
+		return errHeaderInjection
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", s.address)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		err = client.StartTLS(&tls.Config{ServerName: s.host})
+		if err != nil {
+			return err
+		}
+	}
+	if s.auth != nil {
+		err = client.Auth(s.auth)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = client.Mail(s.from)
+	if err != nil {
+		return err
+	}
+	err = client.Rcpt(notification.To)
+	if err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(message(s.from, notification, time.Now()))
+	if err != nil {
+		return err
+	}
+	err = w.Close()
+	if err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// message formats the notification as an RFC 5322 message with CRLF line
+// endings.
+func message(from string, notification *domain.Notification, date time.Time) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", notification.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", notification.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(notification.Body, "\r\n", "\n"), "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return b.Bytes()
+}
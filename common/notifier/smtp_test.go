@@ -0,0 +1,103 @@
+package notifier
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/h4yfans/case-study/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveSMTP accepts one connection on listener, speaks just enough SMTP to
+// take a message and returns the commands and data it received.
+func serveSMTP(listener net.Listener) <-chan []string {
+	received := make(chan []string, 1)
+	go func() {
+		var lines []string
+		defer func() { received <- lines }()
+
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+		reply("220 test")
+		data := false
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			lines = append(lines, line)
+			switch {
+			case data && line == ".":
+				data = false
+				reply("250 queued")
+			case data:
+			case strings.HasPrefix(line, "EHLO"):
+				reply("250 test")
+			case line == "DATA":
+				data = true
+				reply("354 go ahead")
+			case line == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+	return received
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	received := serveSMTP(listener)
+
+	address := listener.Addr().(*net.TCPAddr)
+	s := NewSMTPNotifier(SMTPConfig{Host: "127.0.0.1", Port: address.Port, From: "noreply@test.com"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err = s.Send(ctx, &domain.Notification{To: "kaan@test.com", Subject: "Reset your password", Body: "token: abc\nbye"})
+	require.NoError(t, err)
+
+	lines := <-received
+	assert.Contains(t, lines, "MAIL FROM:<noreply@test.com>")
+	assert.Contains(t, lines, "RCPT TO:<kaan@test.com>")
+	assert.Contains(t, lines, "Subject: Reset your password")
+	assert.Contains(t, lines, "token: abc")
+	assert.Equal(t, "QUIT", lines[len(lines)-1])
+}
+
+func TestSMTPNotifierRejectsHeaderInjection(t *testing.T) {
+	s := NewSMTPNotifier(SMTPConfig{Host: "127.0.0.1", Port: 1, From: "noreply@test.com"})
+
+	err := s.Send(context.Background(), &domain.Notification{To: "kaan@test.com\r\nBcc: x@test.com", Subject: "Hi"})
+	assert.Equal(t, errHeaderInjection, err)
+}
+
+func TestMessage(t *testing.T) {
+	date := time.Date(2021, 9, 1, 12, 0, 0, 0, time.UTC)
+	got := string(message("noreply@test.com", &domain.Notification{To: "kaan@test.com", Subject: "Şifre", Body: "a\nb"}, date))
+
+	assert.Equal(t, "From: noreply@test.com\r\n"+
+		"To: kaan@test.com\r\n"+
+		"Subject: =?utf-8?q?=C5=9Eifre?=\r\n"+
+		"Date: "+date.Format(time.RFC1123Z)+"\r\n"+
+		"MIME-Version: 1.0\r\n"+
+		"Content-Type: text/plain; charset=utf-8\r\n"+
+		"\r\n"+
+		"a\r\nb\r\n", got)
+}
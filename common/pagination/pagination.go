@@ -0,0 +1,69 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// EncodeCursor turns the position of the last returned row into an opaque
+// token. Clients must not depend on its contents.
+func EncodeCursor(position interface{}) (string, error) {
+	data, err := json.Marshal(position)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func DecodeCursor(cursor string, position interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, position)
+}
+
+// Limit applies the default and upper bound to a requested page size.
+func Limit(limit int) int {
+	if limit <= 0 {
+		return DefaultLimit
+	}
+	if limit > MaxLimit {
+		return MaxLimit
+	}
+	return limit
+}
+
+// Link is one entry of an RFC 8288 Link header.
+type Link struct {
+	Rel    string
+	Params map[string]string
+}
+
+// LinkHeader renders links relative to base, replacing the given query
+// parameters and keeping the rest of the request's query.
+func LinkHeader(base *url.URL, links ...Link) string {
+	values := make([]string, 0, len(links))
+	for _, link := range links {
+		target := *base
+		query := target.Query()
+		for key, value := range link.Params {
+			if value == "" {
+				query.Del(key)
+				continue
+			}
+			query.Set(key, value)
+		}
+		target.RawQuery = query.Encode()
+		values = append(values, fmt.Sprintf(`<%s>; rel="%s"`, target.String(), link.Rel))
+	}
+	return strings.Join(values, ", ")
+}
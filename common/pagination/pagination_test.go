@@ -0,0 +1,41 @@
+package pagination
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimit(t *testing.T) {
+	assert.Equal(t, DefaultLimit, Limit(0))
+	assert.Equal(t, DefaultLimit, Limit(-5))
+	assert.Equal(t, 10, Limit(10))
+	assert.Equal(t, MaxLimit, Limit(MaxLimit+1))
+}
+
+func TestCursor(t *testing.T) {
+	type position struct {
+		ID int `json:"id"`
+	}
+
+	cursor, err := EncodeCursor(position{ID: 42})
+	assert.NoError(t, err)
+
+	var decoded position
+	assert.NoError(t, DecodeCursor(cursor, &decoded))
+	assert.Equal(t, 42, decoded.ID)
+
+	assert.Error(t, DecodeCursor("%%%", &decoded))
+}
+
+func TestLinkHeader(t *testing.T) {
+	base, err := url.Parse("/users?limit=5&cursor=abc")
+	assert.NoError(t, err)
+
+	header := LinkHeader(base,
+		Link{Rel: "next", Params: map[string]string{"cursor": "def"}},
+		Link{Rel: "first", Params: map[string]string{"cursor": ""}},
+	)
+	assert.Equal(t, `</users?cursor=def&limit=5>; rel="next", </users?limit=5>; rel="first"`, header)
+}
@@ -0,0 +1,158 @@
+package password
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/h4yfans/case-study/common"
+	"github.com/h4yfans/case-study/domain"
+	"go.uber.org/zap"
+)
+
+// MaxBytes is the longest password bcrypt hashes without silently truncating.
+const MaxBytes = 72
+
+// minPersonalLength keeps very short names from rejecting unrelated passwords.
+const minPersonalLength = 3
+
+const (
+	RuleMinLength    = "min_length"
+	RuleMaxLength    = "max_length"
+	RuleUppercase    = "uppercase"
+	RuleLowercase    = "lowercase"
+	RuleDigit        = "digit"
+	RuleSymbol       = "symbol"
+	RulePersonalInfo = "personal_info"
+	RuleBreached     = "breached"
+)
+
+const field = "password"
+
+type Config struct {
+	MinLength     int
+	MaxLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+}
+
+type Policy struct {
+	config Config
+}
+
+// NewPolicy builds a policy from the given config. The maximum length never
+// exceeds MaxBytes.
+func NewPolicy(config Config) *Policy {
+	if config.MaxLength <= 0 || config.MaxLength > MaxBytes {
+		config.MaxLength = MaxBytes
+	}
+	return &Policy{
+		config: config,
+	}
+}
+
+// Check returns every rule the password breaks. Personal values such as the
+// user's name and email must not appear in the password.
+func (p *Policy) Check(password string, personal ...string) []common.Violation {
+	violations := make([]common.Violation, 0)
+
+	if utf8.RuneCountInString(password) < p.config.MinLength || strings.TrimSpace(password) == "" {
+		violations = append(violations, violation(RuleMinLength, fmt.Sprintf("Password must be at least %d characters", p.config.MinLength)))
+	}
+	if len(password) > p.config.MaxLength {
+		violations = append(violations, violation(RuleMaxLength, fmt.Sprintf("Password must be at most %d bytes", p.config.MaxLength)))
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+
+	if p.config.RequireUpper && !upper {
+		violations = append(violations, violation(RuleUppercase, "Password must contain an uppercase letter"))
+	}
+	if p.config.RequireLower && !lower {
+		violations = append(violations, violation(RuleLowercase, "Password must contain a lowercase letter"))
+	}
+	if p.config.RequireDigit && !digit {
+		violations = append(violations, violation(RuleDigit, "Password must contain a digit"))
+	}
+	if p.config.RequireSymbol && !symbol {
+		violations = append(violations, violation(RuleSymbol, "Password must contain a symbol"))
+	}
+
+	if containsPersonal(password, personal) {
+		violations = append(violations, violation(RulePersonalInfo, "Password must not contain your name or email"))
+	}
+
+	return violations
+}
+
+func containsPersonal(password string, personal []string) bool {
+	lowered := strings.ToLower(password)
+	for _, value := range personal {
+		for _, part := range personalParts(value) {
+			if utf8.RuneCountInString(part) >= minPersonalLength && strings.Contains(lowered, part) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// personalParts splits a name into words and an email into its local part so
+// each is checked on its own.
+func personalParts(value string) []string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if at := strings.LastIndex(value, "@"); at >= 0 {
+		return []string{value, value[:at]}
+	}
+	return append(strings.Fields(value), value)
+}
+
+// Validate applies the policy and the breach list to a new password,
+// reporting every broken rule as a common.ValidationError. A breach list that
+// cannot be checked is logged and reported as common.ServerError.
+func (p *Policy) Validate(ctx context.Context, screener domain.PasswordScreener, password string, personal ...string) error {
+	violations := p.Check(password, personal...)
+
+	breached, err := screener.Breached(ctx, password)
+	if err != nil {
+		zap.L().Error("Password could not be screened", zap.Error(err))
+		return common.ServerError
+	}
+	if breached {
+		violations = append(violations, Breached())
+	}
+
+	if len(violations) > 0 {
+		return &common.ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+// Breached is the violation reported for a password found in a breach list.
+func Breached() common.Violation {
+	return violation(RuleBreached, "Password has appeared in a data breach, choose another")
+}
+
+func violation(rule string, message string) common.Violation {
+	return common.Violation{
+		Field:   field,
+		Rule:    rule,
+		Message: message,
+	}
+}
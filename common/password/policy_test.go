@@ -0,0 +1,67 @@
+package password
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/h4yfans/case-study/common"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeScreener struct {
+	breached bool
+	err      error
+}
+
+func (f fakeScreener) Breached(ctx context.Context, password string) (bool, error) {
+	return f.breached, f.err
+}
+
+func rules(p *Policy, password string, personal ...string) []string {
+	result := make([]string, 0)
+	for _, violation := range p.Check(password, personal...) {
+		result = append(result, violation.Rule)
+	}
+	return result
+}
+
+func TestCheck(t *testing.T) {
+	p := NewPolicy(Config{MinLength: 8, RequireUpper: true, RequireLower: true, RequireDigit: true, RequireSymbol: true})
+
+	assert.Empty(t, rules(p, "Correct-Horse-42"))
+	assert.Equal(t, []string{RuleMinLength, RuleUppercase, RuleDigit, RuleSymbol}, rules(p, "abc"))
+	assert.Equal(t, []string{RuleMinLength, RuleUppercase, RuleLowercase, RuleDigit}, rules(p, "        "))
+	assert.Equal(t, []string{RuleMaxLength}, rules(p, "Aa1!"+strings.Repeat("a", MaxBytes)))
+}
+
+func TestCheckMaxLengthIsCapped(t *testing.T) {
+	p := NewPolicy(Config{MinLength: 1, MaxLength: 1000})
+
+	assert.Equal(t, []string{RuleMaxLength}, rules(p, strings.Repeat("a", MaxBytes+1)))
+	// Multi-byte characters count by their encoded size.
+	assert.Equal(t, []string{RuleMaxLength}, rules(p, strings.Repeat("ş", 37)))
+}
+
+func TestCheckPersonalInfo(t *testing.T) {
+	p := NewPolicy(Config{MinLength: 1})
+
+	assert.Equal(t, []string{RulePersonalInfo}, rules(p, "iamKAAN2021", "Kaan Test", "kaan@test.com"))
+	assert.Equal(t, []string{RulePersonalInfo}, rules(p, "x-kaan.t@example", "K", "kaan.t@example.com"))
+	assert.Empty(t, rules(p, "unrelated-password", "Al", "al@test.com"))
+}
+
+func TestValidate(t *testing.T) {
+	p := NewPolicy(Config{MinLength: 8})
+
+	assert.NoError(t, p.Validate(context.Background(), fakeScreener{}, "correct-horse"))
+
+	var validationErr *common.ValidationError
+	err := p.Validate(context.Background(), fakeScreener{breached: true}, "a")
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []common.Violation{p.Check("a")[0], Breached()}, validationErr.Violations)
+
+	err = p.Validate(context.Background(), fakeScreener{err: errors.New("timeout")}, "correct-horse")
+	assert.Equal(t, common.ServerError, err)
+}
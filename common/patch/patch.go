@@ -0,0 +1,154 @@
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// Content types of the patch formats understood here.
+const (
+	MergePatchType = "application/merge-patch+json"
+	JSONPatchType  = "application/json-patch+json"
+)
+
+var (
+	ErrInvalid    = errors.New("patch: invalid document")
+	ErrTestFailed = errors.New("patch: test operation failed")
+)
+
+// Document is a flat JSON object, the only shape of resource patched here.
+// Members are kept raw so the caller decides how to decode each one.
+type Document map[string]json.RawMessage
+
+// Patch modifies a Document in place.
+type Patch interface {
+	Apply(doc Document) error
+}
+
+// MergePatch is an RFC 7396 JSON Merge Patch: members set to null are
+// removed, all others replace the member of the same name.
+type MergePatch map[string]json.RawMessage
+
+func NewMergePatch(data []byte) (MergePatch, error) {
+	var p MergePatch
+	if err := json.Unmarshal(data, &p); err != nil || p == nil {
+		return nil, ErrInvalid
+	}
+	return p, nil
+}
+
+func (p MergePatch) Apply(doc Document) error {
+	for name, value := range p {
+		if isNull(value) {
+			delete(doc, name)
+			continue
+		}
+		doc[name] = value
+	}
+	return nil
+}
+
+// Operation is one step of an RFC 6902 JSON Patch.
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// JSONPatch is an RFC 6902 JSON Patch limited to top level members, which is
+// all a flat Document has. Operations apply in order and the patch stops at
+// the first one that fails.
+type JSONPatch []Operation
+
+func NewJSONPatch(data []byte) (JSONPatch, error) {
+	var p JSONPatch
+	if err := json.Unmarshal(data, &p); err != nil || p == nil {
+		return nil, ErrInvalid
+	}
+
+	for _, op := range p {
+		if _, err := member(op.Path); err != nil {
+			return nil, err
+		}
+		switch op.Op {
+		case "add", "replace", "test":
+			if op.Value == nil {
+				return nil, ErrInvalid
+			}
+		case "move", "copy":
+			if _, err := member(op.From); err != nil {
+				return nil, err
+			}
+		case "remove":
+		default:
+			return nil, ErrInvalid
+		}
+	}
+	return p, nil
+}
+
+func (p JSONPatch) Apply(doc Document) error {
+	for _, op := range p {
+		name, _ := member(op.Path)
+		from, _ := member(op.From)
+
+		switch op.Op {
+		case "add":
+			doc[name] = op.Value
+		case "replace":
+			if _, ok := doc[name]; !ok {
+				return ErrInvalid
+			}
+			doc[name] = op.Value
+		case "remove":
+			if _, ok := doc[name]; !ok {
+				return ErrInvalid
+			}
+			delete(doc, name)
+		case "move", "copy":
+			value, ok := doc[from]
+			if !ok {
+				return ErrInvalid
+			}
+			if op.Op == "move" {
+				delete(doc, from)
+			}
+			doc[name] = value
+		case "test":
+			value, ok := doc[name]
+			if !ok || !Equal(value, op.Value) {
+				return ErrTestFailed
+			}
+		}
+	}
+	return nil
+}
+
+// member returns the member name a JSON Pointer to a top level member refers
+// to, unescaping ~1 and ~0 as RFC 6901 requires.
+func member(pointer string) (string, error) {
+	if !strings.HasPrefix(pointer, "/") || strings.Contains(pointer[1:], "/") {
+		return "", ErrInvalid
+	}
+	return strings.NewReplacer("~1", "/", "~0", "~").Replace(pointer[1:]), nil
+}
+
+func isNull(value json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(value), []byte("null"))
+}
+
+// Equal compares two JSON values regardless of formatting and member order.
+func Equal(a, b json.RawMessage) bool {
+	var x, y interface{}
+	if json.Unmarshal(a, &x) != nil || json.Unmarshal(b, &y) != nil {
+		return false
+	}
+
+	// Marshal sorts object members, so equal values encode identically.
+	xb, _ := json.Marshal(x)
+	yb, _ := json.Marshal(y)
+	return bytes.Equal(xb, yb)
+}
@@ -0,0 +1,67 @@
+package patch
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func document() Document {
+	return Document{"name": json.RawMessage(`"Kaan"`), "nickname": json.RawMessage(`"kaan"`)}
+}
+
+func TestMergePatch(t *testing.T) {
+	p, err := NewMergePatch([]byte(`{"name":"Ayse","nickname":null,"password":"secret"}`))
+	assert.NoError(t, err)
+
+	doc := document()
+	assert.NoError(t, p.Apply(doc))
+	assert.Equal(t, Document{"name": json.RawMessage(`"Ayse"`), "password": json.RawMessage(`"secret"`)}, doc)
+
+	_, err = NewMergePatch([]byte(`["name"]`))
+	assert.Equal(t, ErrInvalid, err)
+	_, err = NewMergePatch([]byte(`null`))
+	assert.Equal(t, ErrInvalid, err)
+}
+
+func TestJSONPatch(t *testing.T) {
+	p, err := NewJSONPatch([]byte(`[
+		{"op":"test","path":"/name","value":"Kaan"},
+		{"op":"replace","path":"/name","value":"Ayse"},
+		{"op":"remove","path":"/nickname"},
+		{"op":"add","path":"/password","value":"secret"}
+	]`))
+	assert.NoError(t, err)
+
+	doc := document()
+	assert.NoError(t, p.Apply(doc))
+	assert.Equal(t, Document{"name": json.RawMessage(`"Ayse"`), "password": json.RawMessage(`"secret"`)}, doc)
+}
+
+func TestJSONPatchTestFailed(t *testing.T) {
+	p, err := NewJSONPatch([]byte(`[{"op":"test","path":"/name","value":"Ayse"},{"op":"remove","path":"/name"}]`))
+	assert.NoError(t, err)
+
+	doc := document()
+	assert.Equal(t, ErrTestFailed, p.Apply(doc))
+	assert.Contains(t, doc, "name")
+}
+
+func TestJSONPatchInvalid(t *testing.T) {
+	for _, data := range []string{
+		`{"op":"add","path":"/name","value":"Ayse"}`,
+		`[{"op":"add","path":"/name"}]`,
+		`[{"op":"add","path":"name","value":"Ayse"}]`,
+		`[{"op":"add","path":"/profile/name","value":"Ayse"}]`,
+		`[{"op":"merge","path":"/name","value":"Ayse"}]`,
+		`[{"op":"move","path":"/name"}]`,
+	} {
+		_, err := NewJSONPatch([]byte(data))
+		assert.Equal(t, ErrInvalid, err, data)
+	}
+
+	p, err := NewJSONPatch([]byte(`[{"op":"replace","path":"/password","value":"secret"}]`))
+	assert.NoError(t, err)
+	assert.Equal(t, ErrInvalid, p.Apply(document()))
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type Config struct {
+	// ShutdownDelay is how long the server keeps serving after it starts
+	// reporting not ready, so load balancers stop routing to it first.
+	ShutdownDelay time.Duration
+	// ShutdownTimeout bounds how long in-flight requests may take to finish
+	// once the server is asked to stop.
+	ShutdownTimeout time.Duration
+}
+
+// Run serves srv on srv.Addr until ctx is done, then shuts it down
+// gracefully: it calls drain, if given, to start reporting not ready, keeps
+// serving for the shutdown delay, then stops accepting connections and waits
+// up to the shutdown timeout for in-flight requests to finish before closing
+// the rest.
+func Run(ctx context.Context, srv *http.Server, config Config, drain func()) error {
+	listener, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		return err
+	}
+	return serve(ctx, srv, listener, config, drain)
+}
+
+func serve(ctx context.Context, srv *http.Server, listener net.Listener, config Config, drain func()) error {
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.Serve(listener)
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	if drain != nil {
+		drain()
+	}
+	if config.ShutdownDelay > 0 {
+		zap.L().Info("Draining, waiting for load balancers", zap.Duration("delay", config.ShutdownDelay))
+		time.Sleep(config.ShutdownDelay)
+	}
+
+	zap.L().Info("Shutting down, draining connections", zap.Duration("timeout", config.ShutdownTimeout))
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		_ = srv.Close()
+		return err
+	}
+
+	if err := <-errs; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
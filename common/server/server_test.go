@@ -0,0 +1,125 @@
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowServer starts serving a handler that answers only once release is
+// closed, and reports on started when a request is in flight.
+func slowServer(t *testing.T, config Config) (addr string, started chan struct{}, release chan struct{}, cancel context.CancelFunc, done chan error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	started = make(chan struct{})
+	release = make(chan struct{})
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		_, _ = w.Write([]byte("done"))
+	})}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done = make(chan error, 1)
+	go func() {
+		done <- serve(ctx, srv, listener, config, nil)
+	}()
+
+	return listener.Addr().String(), started, release, cancel, done
+}
+
+func TestServeDrainsInFlightRequests(t *testing.T) {
+	addr, started, release, cancel, done := slowServer(t, Config{ShutdownTimeout: 5 * time.Second})
+
+	responses := make(chan string, 1)
+	go func() {
+		res, err := http.Get("http://" + addr)
+		if err != nil {
+			responses <- err.Error()
+			return
+		}
+		defer res.Body.Close()
+		body, _ := ioutil.ReadAll(res.Body)
+		responses <- string(body)
+	}()
+
+	<-started
+	cancel()
+
+	// New connections are refused while the request in flight drains.
+	assert.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", addr)
+		if err == nil {
+			conn.Close()
+		}
+		return err != nil
+	}, time.Second, 10*time.Millisecond)
+
+	close(release)
+	assert.Equal(t, "done", <-responses)
+	assert.NoError(t, <-done)
+}
+
+func TestServeGivesUpAfterShutdownTimeout(t *testing.T) {
+	addr, started, release, cancel, done := slowServer(t, Config{ShutdownTimeout: 50 * time.Millisecond})
+	defer close(release)
+
+	go func() {
+		res, err := http.Get("http://" + addr)
+		if err == nil {
+			res.Body.Close()
+		}
+	}()
+
+	<-started
+	cancel()
+	assert.ErrorIs(t, <-done, context.DeadlineExceeded)
+}
+
+func TestRunReportsListenErrors(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	srv := &http.Server{Addr: listener.Addr().String()}
+	assert.Error(t, Run(context.Background(), srv, Config{}, nil))
+}
+
+func TestServeDrainsBeforeShutdown(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	drained := make(chan struct{})
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-drained:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	})}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- serve(ctx, srv, listener, Config{ShutdownDelay: 200 * time.Millisecond, ShutdownTimeout: time.Second}, func() {
+			close(drained)
+		})
+	}()
+	cancel()
+	<-drained
+
+	// Requests are still served, as not ready, during the shutdown delay.
+	res, err := http.Get("http://" + listener.Addr().String())
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+	assert.NoError(t, <-done)
+}
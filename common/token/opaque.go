@@ -0,0 +1,35 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// NewOpaque returns a random url-safe token together with the hash it should
+// be stored under. Only the hash is ever persisted.
+func NewOpaque() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+
+	value := base64.RawURLEncoding.EncodeToString(b)
+	return value, HashOpaque(value), nil
+}
+
+// HashOpaque returns the hex encoded sha256 of an opaque token.
+func HashOpaque(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewID returns a random 128 bit hex identifier.
+func NewID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
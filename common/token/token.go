@@ -0,0 +1,165 @@
+package token
+
+import (
+	"crypto/rsa"
+	"io/ioutil"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/h4yfans/case-study/common"
+	"go.uber.org/zap"
+)
+
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+)
+
+type Config struct {
+	Algorithm        string
+	Secret           string
+	PrivateKeyPath   string
+	PublicKeyPath    string
+	Issuer           string
+	AccessTokenTTL   time.Duration
+	RefreshTokenTTL  time.Duration
+	PasswordResetTTL time.Duration
+	VerificationTTL  time.Duration
+}
+
+type Claims struct {
+	jwt.RegisteredClaims
+}
+
+// UserID returns the user id stored in the subject claim.
+func (c *Claims) UserID() (int, error) {
+	return strconv.Atoi(c.Subject)
+}
+
+type Manager struct {
+	method     jwt.SigningMethod
+	signKey    interface{}
+	verifyKey  interface{}
+	issuer     string
+	ttl        time.Duration
+	refreshTTL time.Duration
+	resetTTL   time.Duration
+	verifyTTL  time.Duration
+}
+
+// NewManager builds a token manager from the given config. Keys are loaded
+// once here so a misconfiguration stops the service at startup.
+func NewManager(config Config) *Manager {
+	manager := &Manager{
+		issuer:     config.Issuer,
+		ttl:        config.AccessTokenTTL,
+		refreshTTL: config.RefreshTokenTTL,
+		resetTTL:   config.PasswordResetTTL,
+		verifyTTL:  config.VerificationTTL,
+	}
+
+	switch config.Algorithm {
+	case HS256:
+		if config.Secret == "" {
+			zap.L().Fatal("JWT secret is required for HS256")
+		}
+		manager.method = jwt.SigningMethodHS256
+		manager.signKey = []byte(config.Secret)
+		manager.verifyKey = []byte(config.Secret)
+	case RS256:
+		privateKey, err := loadPrivateKey(config.PrivateKeyPath)
+		if err != nil {
+			zap.L().Fatal("JWT private key could not be loaded", zap.Error(err), zap.String("path", config.PrivateKeyPath))
+		}
+		publicKey, err := loadPublicKey(config.PublicKeyPath)
+		if err != nil {
+			zap.L().Fatal("JWT public key could not be loaded", zap.Error(err), zap.String("path", config.PublicKeyPath))
+		}
+		manager.method = jwt.SigningMethodRS256
+		manager.signKey = privateKey
+		manager.verifyKey = publicKey
+	default:
+		zap.L().Fatal("Unknown JWT algorithm", zap.String("algorithm", config.Algorithm))
+	}
+
+	return manager
+}
+
+// Sign issues an access token for the given user and returns it with its expiry.
+func (m *Manager) Sign(userID int) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(m.ttl)
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    m.issuer,
+			Subject:   strconv.Itoa(userID),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	signed, err := jwt.NewWithClaims(m.method, claims).SignedString(m.signKey)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return signed, expiresAt, nil
+}
+
+// Parse verifies the signature, algorithm, issuer and expiry of the token.
+func (m *Manager) Parse(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	parsed, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		if t.Method.Alg() != m.method.Alg() {
+			return nil, common.InvalidToken
+		}
+		return m.verifyKey, nil
+	})
+	if err != nil || !parsed.Valid {
+		return nil, common.InvalidToken
+	}
+
+	if m.issuer != "" && !claims.VerifyIssuer(m.issuer, true) {
+		return nil, common.InvalidToken
+	}
+
+	return claims, nil
+}
+
+// TTL is the lifetime of issued access tokens.
+func (m *Manager) TTL() time.Duration {
+	return m.ttl
+}
+
+// RefreshTTL is the lifetime of issued refresh tokens.
+func (m *Manager) RefreshTTL() time.Duration {
+	return m.refreshTTL
+}
+
+// PasswordResetTTL is the lifetime of password reset tokens.
+func (m *Manager) PasswordResetTTL() time.Duration {
+	return m.resetTTL
+}
+
+// VerificationTTL is the lifetime of email verification tokens.
+func (m *Manager) VerificationTTL() time.Duration {
+	return m.verifyTTL
+}
+
+func loadPrivateKey(path string) (*rsa.PrivateKey, error) {
+	pem, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return jwt.ParseRSAPrivateKeyFromPEM(pem)
+}
+
+func loadPublicKey(path string) (*rsa.PublicKey, error) {
+	pem, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return jwt.ParseRSAPublicKeyFromPEM(pem)
+}
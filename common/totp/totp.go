@@ -0,0 +1,209 @@
+package totp
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	Digits = 6
+	Period = 30 * time.Second
+
+	modulo = 1000000 // 10^Digits
+
+	// skew is the number of periods accepted before and after the current one
+	// to tolerate clock drift on the user's device.
+	skew = 1
+
+	secretSize = 20
+
+	RecoveryCodeCount = 10
+	// Recovery codes are stored as plain SHA-256 hashes so they can be looked
+	// up, which is only safe while they are too long to guess offline.
+	recoveryCodeSize = 10 // bytes, 80 bits in 16 base32 characters
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+type Config struct {
+	Issuer        string
+	EncryptionKey string
+	ChallengeTTL  time.Duration
+}
+
+// Manager generates and validates RFC 6238 codes. Secrets are encrypted with
+// AES-GCM before they are stored.
+type Manager struct {
+	issuer       string
+	aead         cipher.AEAD
+	challengeTTL time.Duration
+}
+
+// NewManager builds a TOTP manager from the given config. The encryption key
+// is a base64 encoded 32 byte AES key.
+func NewManager(config Config) *Manager {
+	key, err := base64.StdEncoding.DecodeString(config.EncryptionKey)
+	if err != nil || len(key) != 32 {
+		zap.L().Fatal("TOTP encryption key must be 32 bytes encoded as base64", zap.Error(err))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		zap.L().Fatal("TOTP cipher could not be created", zap.Error(err))
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		zap.L().Fatal("TOTP cipher could not be created", zap.Error(err))
+	}
+
+	return &Manager{
+		issuer:       config.Issuer,
+		aead:         aead,
+		challengeTTL: config.ChallengeTTL,
+	}
+}
+
+// ChallengeTTL is how long a login challenge may wait for its second factor.
+func (m *Manager) ChallengeTTL() time.Duration {
+	return m.challengeTTL
+}
+
+// GenerateSecret returns a new base32 encoded shared secret.
+func (m *Manager) GenerateSecret() (string, error) {
+	secret := make([]byte, secretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return encoding.EncodeToString(secret), nil
+}
+
+// URI returns the otpauth:// URI authenticator apps read from a QR code.
+func (m *Manager) URI(account string, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", m.issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(Digits))
+	query.Set("period", fmt.Sprint(int(Period.Seconds())))
+
+	return (&url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + m.issuer + ":" + account,
+		RawQuery: query.Encode(),
+	}).String()
+}
+
+// Match reports whether code is valid for secret at the given time and
+// returns the time step it belongs to, so callers can reject replays.
+func (m *Manager) Match(secret string, code string, at time.Time) (int64, bool) {
+	if len(code) != Digits {
+		return 0, false
+	}
+
+	counter := at.Unix() / int64(Period.Seconds())
+	for i := -skew; i <= skew; i++ {
+		expected, err := Code(secret, counter+int64(i))
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return counter + int64(i), true
+		}
+	}
+	return 0, false
+}
+
+// Seal encrypts the secret for storage.
+func (m *Manager) Seal(secret string) (string, error) {
+	nonce := make([]byte, m.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	sealed := m.aead.Seal(nonce, nonce, []byte(secret), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a secret sealed by Seal.
+func (m *Manager) Open(sealed string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", err
+	}
+
+	if len(data) < m.aead.NonceSize() {
+		return "", errors.New("sealed secret is too short")
+	}
+
+	nonce, ciphertext := data[:m.aead.NonceSize()], data[m.aead.NonceSize():]
+	secret, err := m.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return string(secret), nil
+}
+
+// Code computes the HOTP value (RFC 4226) of secret for the given counter.
+func Code(secret string, counter int64) (string, error) {
+	key, err := encoding.DecodeString(secret)
+	if err != nil {
+		return "", err
+	}
+
+	message := make([]byte, 8)
+	binary.BigEndian.PutUint64(message, uint64(counter))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(message)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", Digits, value%modulo), nil
+}
+
+// NewRecoveryCodes returns a fresh set of recovery codes formatted as
+// xxxx-xxxx-xxxx-xxxx for display.
+func NewRecoveryCodes() ([]string, error) {
+	codes := make([]string, RecoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, recoveryCodeSize)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		code := strings.ToLower(encoding.EncodeToString(b))
+		groups := make([]string, 0, len(code)/4)
+		for len(code) > 0 {
+			groups = append(groups, code[:4])
+			code = code[4:]
+		}
+		codes[i] = strings.Join(groups, "-")
+	}
+	return codes, nil
+}
+
+// NormalizeRecoveryCode strips the formatting users may or may not type so the
+// code can be hashed and compared.
+func NormalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
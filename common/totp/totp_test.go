@@ -0,0 +1,81 @@
+package totp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rfcSecret is the SHA1 seed "12345678901234567890" from RFC 6238 appendix B.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func newManager() *Manager {
+	return NewManager(Config{
+		Issuer:        "case-study",
+		EncryptionKey: "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=",
+		ChallengeTTL:  time.Minute,
+	})
+}
+
+func TestCode(t *testing.T) {
+	// The RFC lists 8 digit values, these are their last 6 digits.
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+
+	for unix, expected := range vectors {
+		code, err := Code(rfcSecret, unix/int64(Period.Seconds()))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, code, "time %d", unix)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	m := newManager()
+	at := time.Unix(1111111109, 0)
+
+	counter, ok := m.Match(rfcSecret, "081804", at)
+	assert.True(t, ok)
+	assert.Equal(t, int64(1111111109/30), counter)
+
+	_, ok = m.Match(rfcSecret, "081804", at.Add(Period))
+	assert.True(t, ok, "previous step is accepted")
+
+	_, ok = m.Match(rfcSecret, "081804", at.Add(3*Period))
+	assert.False(t, ok)
+
+	_, ok = m.Match(rfcSecret, "000000", at)
+	assert.False(t, ok)
+}
+
+func TestSealOpen(t *testing.T) {
+	m := newManager()
+
+	sealed, err := m.Seal(rfcSecret)
+	assert.NoError(t, err)
+	assert.NotContains(t, sealed, rfcSecret)
+
+	opened, err := m.Open(sealed)
+	assert.NoError(t, err)
+	assert.Equal(t, rfcSecret, opened)
+
+	_, err = m.Open("dGFtcGVyZWQ=")
+	assert.Error(t, err)
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := NewRecoveryCodes()
+	assert.NoError(t, err)
+	assert.Len(t, codes, RecoveryCodeCount)
+	for _, code := range codes {
+		assert.Regexp(t, `^[a-z2-7]{4}(-[a-z2-7]{4}){3}$`, code)
+		assert.Len(t, NormalizeRecoveryCode(code), 16)
+	}
+	assert.Equal(t, "abcdefgh", NormalizeRecoveryCode(" ABCD-EFGH "))
+}
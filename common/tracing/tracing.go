@@ -0,0 +1,139 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/h4yfans/case-study/common"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	ExporterNone   = "NONE"
+	ExporterOTLP   = "OTLP"
+	ExporterStdout = "STDOUT"
+)
+
+const tracerName = "github.com/h4yfans/case-study"
+
+type Config struct {
+	ServiceName string
+	Exporter    string
+	// Endpoint is the host:port of the OTLP/HTTP collector.
+	Endpoint string
+	Insecure bool
+	// SampleRatio is the share of new traces recorded. Requests that arrive
+	// with a sampled parent are always recorded.
+	SampleRatio float64
+}
+
+// Initialize installs the global tracer provider and the W3C trace context
+// and baggage propagators. The returned function flushes the spans not yet
+// exported and must be called before the process exits. With ExporterNone
+// spans are still propagated but not recorded.
+func Initialize(config Config) func(context.Context) error {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch config.Exporter {
+	case ExporterNone:
+		return func(context.Context) error { return nil }
+	case ExporterOTLP:
+		options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(config.Endpoint)}
+		if config.Insecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(context.Background(), options...)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	default:
+		zap.L().Fatal("Unknown tracing exporter", zap.String("exporter", config.Exporter))
+	}
+	if err != nil {
+		zap.L().Fatal("Tracing exporter could not be created", zap.Error(err), zap.String("exporter", config.Exporter))
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceNameKey.String(config.ServiceName),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown
+}
+
+// Start starts a span named name as a child of the span in ctx, if any.
+func Start(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// End ends span, recording err on it. Only errors that surface as server
+// errors mark the span as failed; rejected requests are expected.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		if common.GetStatusCode(err) >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+	span.End()
+}
+
+// Middleware starts the server span of every request, continuing the trace of
+// the caller if it sent one, and names it after the route of router that r
+// matches. It goes outermost so that everything after it, the access log
+// included, runs inside the span.
+func Middleware(router *mux.Router, service string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+			route := common.RouteTemplate(router, r)
+			name := route
+			if name == "" {
+				name = fmt.Sprintf("HTTP %s route not found", r.Method)
+			}
+
+			ctx, span := otel.Tracer(tracerName).Start(ctx, name,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(semconv.NetAttributesFromHTTPRequest("tcp", r)...),
+				trace.WithAttributes(semconv.EndUserAttributesFromHTTPRequest(r)...),
+				trace.WithAttributes(semconv.HTTPServerAttributesFromHTTPRequest(service, route, r)...),
+			)
+			defer span.End()
+
+			recorder := &statusRecorder{ResponseWriter: w, code: http.StatusOK}
+			next.ServeHTTP(recorder, r.WithContext(ctx))
+
+			span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(recorder.code)...)
+			span.SetStatus(semconv.SpanStatusFromHTTPStatusCode(recorder.code))
+		})
+	}
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	s.code = code
+	s.ResponseWriter.WriteHeader(code)
+}
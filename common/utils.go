@@ -3,11 +3,36 @@ package common
 import (
 	"encoding/json"
 	"errors"
+	"net"
 	"net/http"
+
+	"github.com/gorilla/mux"
 )
 
+// RequestIDHeader carries the ID that identifies a request in the logs.
+const RequestIDHeader = "X-Request-ID"
+
 type ResponseError struct {
-	Error string `json:"error,omitempty"`
+	Error      string      `json:"error,omitempty"`
+	Violations []Violation `json:"violations,omitempty"`
+	RequestID  string      `json:"request_id,omitempty"`
+}
+
+// Violation is a single validation rule a request broke.
+type Violation struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// ValidationError reports every violated rule at once so clients can show
+// them together.
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	return "Validation failed"
 }
 
 var (
@@ -15,6 +40,24 @@ var (
 	ServerError      = errors.New("Server error")
 	UserAlreadyExist = errors.New("User with that email already exists")
 	UserNotExist     = errors.New("User with that id does not exist")
+
+	InvalidCredentials = errors.New("Invalid email or password")
+	InvalidToken       = errors.New("Invalid token")
+	Unauthorized       = errors.New("Authentication required")
+	Forbidden          = errors.New("You are not allowed to perform this action")
+	EmailNotVerified   = errors.New("Email address is not verified")
+
+	TwoFactorEnabled     = errors.New("Two-factor authentication is already enabled")
+	InvalidTwoFactorCode = errors.New("Invalid two-factor code")
+
+	TooManyAttempts = errors.New("Too many failed attempts, try again later")
+	AccountLocked   = errors.New("Account is temporarily locked")
+
+	PreconditionFailed   = errors.New("User was modified since it was last read")
+	UnsupportedMediaType = errors.New("Unsupported content type")
+
+	Conflict = errors.New("Request conflicted with a concurrent one, try again")
+	Timeout  = errors.New("Request took too long, try again later")
 )
 
 func GetStatusCode(err error) int {
@@ -22,21 +65,59 @@ func GetStatusCode(err error) int {
 		return http.StatusOK
 	}
 
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return http.StatusBadRequest
+	}
+
 	switch err {
 	case BadRequest:
 		return http.StatusBadRequest
 	case ServerError:
 		return http.StatusInternalServerError
-	case UserAlreadyExist:
+	case UserAlreadyExist, Forbidden, EmailNotVerified, TwoFactorEnabled:
 		return http.StatusForbidden
 	case UserNotExist:
 		return http.StatusNotFound
+	case InvalidCredentials, InvalidToken, Unauthorized, InvalidTwoFactorCode:
+		return http.StatusUnauthorized
+	case TooManyAttempts:
+		return http.StatusTooManyRequests
+	case AccountLocked:
+		return http.StatusLocked
+	case PreconditionFailed:
+		return http.StatusPreconditionFailed
+	case UnsupportedMediaType:
+		return http.StatusUnsupportedMediaType
+	case Conflict:
+		return http.StatusConflict
+	case Timeout:
+		return http.StatusServiceUnavailable
 	default:
 		return http.StatusInternalServerError
 	}
 }
 
+// NewResponseError builds the response body for err, including violations
+// when err is a ValidationError.
+func NewResponseError(err error) ResponseError {
+	response := ResponseError{Error: err.Error()}
+
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		response.Violations = validationErr.Violations
+	}
+
+	return response
+}
+
+// RespondWithJSON writes payload as the response. Error payloads get the
+// request ID set on the response, if any, so clients can quote it.
 func RespondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
+	if response, ok := payload.(ResponseError); ok && response.RequestID == "" {
+		response.RequestID = w.Header().Get(RequestIDHeader)
+		payload = response
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(code)
@@ -45,3 +126,27 @@ func RespondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
 		_, _ = w.Write(response)
 	}
 }
+
+// ClientIP returns the address of the peer that sent the request.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// RouteTemplate returns the path template of the route of router that r
+// matches, or "" if it matches none.
+func RouteTemplate(router *mux.Router, r *http.Request) string {
+	var match mux.RouteMatch
+	if !router.Match(r, &match) || match.Route == nil {
+		return ""
+	}
+
+	template, err := match.Route.GetPathTemplate()
+	if err != nil {
+		return ""
+	}
+	return template
+}
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS refresh_tokens
+(
+    id         serial PRIMARY KEY,
+    user_id    INTEGER            NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    family_id  VARCHAR(64)        NOT NULL,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMPTZ        NOT NULL,
+    used_at    TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ        NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS refresh_tokens_family_id_idx ON refresh_tokens (family_id);
+CREATE INDEX IF NOT EXISTS refresh_tokens_user_id_idx ON refresh_tokens (user_id);
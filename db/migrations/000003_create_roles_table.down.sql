@@ -0,0 +1,4 @@
+alter table users drop column role;
+drop table role_permissions;
+drop table permissions;
+drop table roles;
\ No newline at end of file
@@ -0,0 +1,41 @@
+CREATE TABLE IF NOT EXISTS roles
+(
+    name VARCHAR(50) PRIMARY KEY
+);
+
+CREATE TABLE IF NOT EXISTS permissions
+(
+    name VARCHAR(100) PRIMARY KEY
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions
+(
+    role       VARCHAR(50)  NOT NULL REFERENCES roles (name) ON DELETE CASCADE,
+    permission VARCHAR(100) NOT NULL REFERENCES permissions (name) ON DELETE CASCADE,
+    PRIMARY KEY (role, permission)
+);
+
+INSERT INTO roles (name)
+VALUES ('admin'),
+       ('user');
+
+INSERT INTO permissions (name)
+VALUES ('users:read'),
+       ('users:update'),
+       ('users:delete'),
+       ('users:list'),
+       ('users:read:any'),
+       ('users:update:any'),
+       ('users:delete:any');
+
+INSERT INTO role_permissions (role, permission)
+SELECT 'admin', name
+FROM permissions;
+
+INSERT INTO role_permissions (role, permission)
+VALUES ('user', 'users:read'),
+       ('user', 'users:update'),
+       ('user', 'users:delete');
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS role VARCHAR(50) NOT NULL DEFAULT 'user' REFERENCES roles (name);
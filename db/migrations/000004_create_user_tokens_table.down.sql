@@ -0,0 +1 @@
+drop table user_tokens;
\ No newline at end of file
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS user_tokens
+(
+    id         serial PRIMARY KEY,
+    user_id    INTEGER            NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    purpose    VARCHAR(32)        NOT NULL,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMPTZ        NOT NULL,
+    used_at    TIMESTAMPTZ,
+    created_at TIMESTAMPTZ        NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS user_tokens_user_id_purpose_idx ON user_tokens (user_id, purpose);
@@ -0,0 +1 @@
+alter table users drop column email_verified_at;
\ No newline at end of file
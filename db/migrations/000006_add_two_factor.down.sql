@@ -0,0 +1,2 @@
+drop table recovery_codes;
+alter table users drop column totp_secret, drop column totp_enabled_at, drop column totp_last_counter;
\ No newline at end of file
@@ -0,0 +1,15 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS totp_secret       TEXT,
+    ADD COLUMN IF NOT EXISTS totp_enabled_at   TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS totp_last_counter BIGINT;
+
+CREATE TABLE IF NOT EXISTS recovery_codes
+(
+    id         serial PRIMARY KEY,
+    user_id    INTEGER     NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    code_hash  VARCHAR(64) NOT NULL,
+    used_at    TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS recovery_codes_user_id_idx ON recovery_codes (user_id);
@@ -0,0 +1,2 @@
+delete from permissions where name = 'users:unlock';
+drop table login_attempts;
\ No newline at end of file
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS login_attempts
+(
+    key           VARCHAR(320) PRIMARY KEY,
+    failures      INTEGER     NOT NULL DEFAULT 0,
+    blocked_until TIMESTAMPTZ,
+    locked        BOOLEAN     NOT NULL DEFAULT false,
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+INSERT INTO permissions (name)
+VALUES ('users:unlock');
+
+INSERT INTO role_permissions (role, permission)
+VALUES ('admin', 'users:unlock');
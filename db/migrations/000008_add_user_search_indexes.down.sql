@@ -0,0 +1,3 @@
+drop index if exists users_email_trgm_idx;
+drop index if exists users_name_trgm_idx;
+drop index if exists users_search_document_idx;
@@ -0,0 +1,2 @@
+delete from permissions where name in ('users:restore', 'users:purge');
+alter table users drop column if exists deleted_at;
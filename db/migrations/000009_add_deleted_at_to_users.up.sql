@@ -0,0 +1,10 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+INSERT INTO permissions (name)
+VALUES ('users:restore'),
+       ('users:purge');
+
+INSERT INTO role_permissions (role, permission)
+VALUES ('admin', 'users:restore'),
+       ('admin', 'users:purge');
@@ -0,0 +1,3 @@
+alter table users
+    alter column email type varchar(50),
+    alter column pending_email type varchar(50);
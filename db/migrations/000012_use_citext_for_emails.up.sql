@@ -0,0 +1,8 @@
+-- Run `go run ./cmd/emailduplicates` first: this fails if two accounts have
+-- emails that differ only in case. Run it again with -rewrite afterwards to
+-- store the emails in the normalized form lookups use.
+CREATE EXTENSION IF NOT EXISTS citext;
+
+ALTER TABLE users
+    ALTER COLUMN email TYPE CITEXT,
+    ALTER COLUMN pending_email TYPE CITEXT;
@@ -0,0 +1,129 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/h4yfans/case-study/models"
+)
+
+const (
+	TokenPurposePasswordReset     = "password_reset"
+	TokenPurposeEmailVerification = "email_verification"
+	TokenPurposeTwoFactor         = "two_factor_challenge"
+	TokenPurposeEmailChange       = "email_change"
+)
+
+type RefreshTokenRepository interface {
+	Create(c context.Context, token *models.RefreshToken) (*models.RefreshToken, error)
+	GetByHash(c context.Context, hash string) (*models.RefreshToken, error)
+	MarkUsed(c context.Context, id int) error
+	RevokeFamily(c context.Context, familyID string) error
+	RevokeAllForUser(c context.Context, userID int) error
+}
+
+// UserTokenRepository stores hashed single-use tokens sent to users, such as
+// password reset tokens. Tokens are looked up by purpose and hash.
+type UserTokenRepository interface {
+	Create(c context.Context, token *models.UserToken) (*models.UserToken, error)
+	GetByHash(c context.Context, purpose string, hash string) (*models.UserToken, error)
+	MarkUsed(c context.Context, id int) error
+	InvalidateAll(c context.Context, userID int, purpose string) error
+}
+
+// RecoveryCodeRepository stores hashed one-time recovery codes that stand in
+// for a TOTP code when the user has lost their device.
+type RecoveryCodeRepository interface {
+	Replace(c context.Context, userID int, hashes []string) error
+	Use(c context.Context, userID int, hash string) error
+}
+
+// LoginAttemptRepository counts failed password and second factor checks per
+// key, see lockout.EmailKey and lockout.IPKey.
+type LoginAttemptRepository interface {
+	Get(c context.Context, key string) (*models.LoginAttempt, error)
+	RecordFailure(c context.Context, key string, window time.Duration) (int, error)
+	Block(c context.Context, key string, until time.Time, locked bool) error
+	Reset(c context.Context, key string) error
+}
+
+type AuthUsecase interface {
+	Login(c context.Context, credentials *LoginRequest) (*TokenResponse, error)
+	Refresh(c context.Context, refreshToken string) (*TokenResponse, error)
+	Logout(c context.Context, refreshToken string) error
+	ForgotPassword(c context.Context, email string) error
+	ResetPassword(c context.Context, request *PasswordResetRequest) error
+	Authenticate(c context.Context, accessToken string) (*Principal, error)
+	LoginTwoFactor(c context.Context, request *TwoFactorLoginRequest) (*TokenResponse, error)
+	EnrollTOTP(c context.Context, principal *Principal) (*TOTPEnrollmentResponse, error)
+	ConfirmTOTP(c context.Context, principal *Principal, code string) (*RecoveryCodesResponse, error)
+}
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	UserID      int
+	Role        string
+	Permissions []string
+}
+
+func (p *Principal) Can(permission string) bool {
+	if p == nil {
+		return false
+	}
+	for _, granted := range p.Permissions {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	IP       string `json:"-"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type PasswordResetRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+	IP             string `json:"-"`
+}
+
+type TOTPConfirmRequest struct {
+	Code string `json:"code"`
+}
+
+// TokenResponse carries either the issued tokens or, when the user has a
+// second factor enabled, the challenge token to complete the login with.
+type TokenResponse struct {
+	AccessToken  string `json:"access_token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	TokenType    string `json:"token_type,omitempty"`
+	ExpiresIn    int    `json:"expires_in,omitempty"`
+
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	ChallengeToken    string `json:"challenge_token,omitempty"`
+}
+
+type TOTPEnrollmentResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
@@ -0,0 +1,36 @@
+package domain
+
+import "context"
+
+const (
+	HealthStatusUp   = "up"
+	HealthStatusDown = "down"
+)
+
+type HealthRepository interface {
+	Ping(c context.Context) error
+	// MigrationState returns the schema version the migrations left the
+	// database at, 0 if none ran, and whether the last one failed midway.
+	MigrationState(c context.Context) (version int64, dirty bool, err error)
+}
+
+// HealthUsecase reports whether the process is alive and whether it can
+// serve traffic. Drain marks it not ready for good, ahead of a shutdown.
+type HealthUsecase interface {
+	Live(c context.Context) *HealthResponse
+	Ready(c context.Context) *HealthResponse
+	Drain()
+}
+
+type HealthCheck struct {
+	Name      string                 `json:"name"`
+	Status    string                 `json:"status"`
+	LatencyMS float64                `json:"latency_ms"`
+	Error     string                 `json:"error,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+}
+
+type HealthResponse struct {
+	Status string        `json:"status"`
+	Checks []HealthCheck `json:"checks,omitempty"`
+}
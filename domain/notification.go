@@ -0,0 +1,15 @@
+package domain
+
+import (
+	"context"
+)
+
+type Notifier interface {
+	Send(c context.Context, notification *Notification) error
+}
+
+type Notification struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
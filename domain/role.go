@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"context"
+)
+
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+)
+
+// Permissions without the ":any" suffix only apply to the caller's own record.
+const (
+	PermissionUsersRead      = "users:read"
+	PermissionUsersUpdate    = "users:update"
+	PermissionUsersDelete    = "users:delete"
+	PermissionUsersList      = "users:list"
+	PermissionUsersReadAny   = "users:read:any"
+	PermissionUsersUpdateAny = "users:update:any"
+	PermissionUsersDeleteAny = "users:delete:any"
+	PermissionUsersUnlock    = "users:unlock"
+	PermissionUsersRestore   = "users:restore"
+	PermissionUsersPurge     = "users:purge"
+)
+
+type RoleRepository interface {
+	GetPermissions(c context.Context, role string) ([]string, error)
+}
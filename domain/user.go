@@ -2,30 +2,166 @@ package domain
 
 import (
 	"context"
+	"time"
 
+	"github.com/h4yfans/case-study/common/patch"
 	"github.com/h4yfans/case-study/models"
 )
 
 type UserRepository interface {
 	Create(c context.Context, user *models.User) (*models.User, error)
-	Update(c context.Context, user *models.User) (*models.User, error)
-	Delete(c context.Context, id int) error
+	Update(c context.Context, user *models.User, columns []string) (*models.User, error)
+	UpdatePassword(c context.Context, id int, password string) error
+	MarkEmailVerified(c context.Context, id int) error
+	SetPendingEmail(c context.Context, id int, email string) error
+	ChangeEmail(c context.Context, id int, email string) error
+	SetTOTPSecret(c context.Context, id int, secret string) error
+	EnableTOTP(c context.Context, id int) error
+	UseTOTPCounter(c context.Context, id int, counter int64) error
+	Delete(c context.Context, user *models.User) error
+	Restore(c context.Context, id int) error
+	Purge(c context.Context, id int) error
 	GetByID(c context.Context, id int) (*models.User, error)
-	GetAllUser(c context.Context) (models.UserSlice, error)
+	GetByEmail(c context.Context, email string) (*models.User, error)
+	GetAllUser(c context.Context, query *UserListQuery) (models.UserSlice, error)
+	CountUsers(c context.Context, filters []UserFilter) (int64, error)
+	Search(c context.Context, query string, limit int) ([]UserSearchHit, error)
 }
 
+// UserUsecase methods other than Create receive the acting principal and
+// decide whether it may touch the target user. Update and Delete also take
+// the If-Match header of the request, if any, and report
+// common.PreconditionFailed when it no longer matches the user.
 type UserUsecase interface {
 	Create(c context.Context, user *models.User) (*UserResponse, error)
-	Update(c context.Context, user *models.User) (*UserResponse, error)
-	Delete(c context.Context, id int) error
-	GetByID(c context.Context, id int) (*UserResponse, error)
-	GetAllUser(c context.Context) ([]UserResponse, error)
+	Update(c context.Context, principal *Principal, id int, changes patch.Patch, ifMatch string) (*UserResponse, error)
+	Delete(c context.Context, principal *Principal, id int, ifMatch string) error
+	Restore(c context.Context, principal *Principal, id int) error
+	Purge(c context.Context, principal *Principal, id int) error
+	GetByID(c context.Context, principal *Principal, id int) (*UserResponse, error)
+	GetAllUser(c context.Context, principal *Principal, params *UserListParams) (*UserListResponse, error)
+	Search(c context.Context, principal *Principal, params *UserSearchParams) (*UserSearchResponse, error)
+	Unlock(c context.Context, principal *Principal, id int) error
+	VerifyEmail(c context.Context, token string) error
+	ResendVerification(c context.Context, email string) error
+	RequestEmailChange(c context.Context, principal *Principal, id int, email string) error
+	ConfirmEmailChange(c context.Context, token string) error
+}
+
+// Filter operators accepted on the user list.
+const (
+	FilterEQ       = "eq"
+	FilterPrefix   = "prefix"
+	FilterSuffix   = "suffix"
+	FilterContains = "contains"
+	FilterIn       = "in"
+)
+
+var (
+	// UserFilterFields and UserSortFields whitelist what clients may filter
+	// and sort the user list by.
+	UserFilterFields = []string{"name", "email"}
+	UserSortFields   = []string{"id", "name", "email"}
+
+	FilterOperators = []string{FilterEQ, FilterPrefix, FilterSuffix, FilterContains, FilterIn}
+)
+
+// UserFilter narrows the user list to users whose Field matches Values under
+// Operator. Only FilterIn takes more than one value.
+type UserFilter struct {
+	Field    string
+	Operator string
+	Values   []string
+}
+
+type UserSort struct {
+	Field      string
+	Descending bool
+}
+
+// UserListParams are the paging, filtering and sorting options of a user
+// list request. Cursor selects keyset paging, Page selects offset paging with
+// totals.
+type UserListParams struct {
+	Limit   int
+	Cursor  string
+	Page    int
+	Filters []UserFilter
+	Sort    []UserSort
+}
+
+// UserListQuery is one page of users as the repository fetches it, either
+// after a cursor or at an offset. Sort always ends with id so the order is
+// total and cursors are stable.
+type UserListQuery struct {
+	Limit   int
+	After   *UserCursor
+	Offset  int
+	Filters []UserFilter
+	Sort    []UserSort
+}
+
+// UserCursor is the keyset position of the last user on a page: its id and
+// its value for each field of the sort, in order. Values holds an empty
+// placeholder where the sort field is id itself.
+type UserCursor struct {
+	ID     int      `json:"id"`
+	Values []string `json:"values,omitempty"`
+}
+
+type UserSearchParams struct {
+	Query string
+	Limit int
+}
+
+// UserSearchHit is a user matched by a search and how relevant the match is,
+// higher being better.
+type UserSearchHit struct {
+	User  *models.User
+	Score float64
+}
+
+// SignupRequest holds the only fields a client may set when signing up.
+type SignupRequest struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+type EmailChangeRequest struct {
+	Email string `json:"email"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email"`
+}
+
+// PasswordHasher hashes passwords and verifies them against stored hashes.
+// VerifyPassword also reports whether a matching hash is outdated and should
+// be replaced with a fresh one.
+type PasswordHasher interface {
+	HashPassword(password string) (string, error)
+	VerifyPassword(hash string, password string) (ok bool, rehash bool, err error)
+}
+
+// PasswordScreener reports whether a password is known from data breaches.
+type PasswordScreener interface {
+	Breached(c context.Context, password string) (bool, error)
 }
 
 type UserResponse struct {
 	ID    int    `json:"id"`
 	Name  string `json:"name"`
 	Email string `json:"email"`
+	Role  string `json:"role"`
+
+	EmailVerified    bool      `json:"email_verified"`
+	TwoFactorEnabled bool      `json:"two_factor_enabled"`
+	UpdatedAt        time.Time `json:"updated_at"`
 }
 
 func UserSerializer(user *models.User) *UserResponse {
@@ -33,5 +169,27 @@ func UserSerializer(user *models.User) *UserResponse {
 		ID:    user.ID,
 		Name:  user.Name,
 		Email: user.Email,
+		Role:  user.Role,
+
+		EmailVerified:    user.EmailVerifiedAt.Valid,
+		TwoFactorEnabled: user.TotpEnabledAt.Valid,
+		UpdatedAt:        user.UpdatedAt,
 	}
 }
+
+type UserListResponse struct {
+	Users      []UserResponse `json:"users"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+	Page       int            `json:"page,omitempty"`
+	TotalCount int64          `json:"total_count,omitempty"`
+	TotalPages int            `json:"total_pages,omitempty"`
+}
+
+type UserSearchResult struct {
+	UserResponse
+	Score float64 `json:"score"`
+}
+
+type UserSearchResponse struct {
+	Users []UserSearchResult `json:"users"`
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/h4yfans/case-study/common"
+	"github.com/h4yfans/case-study/common/middleware"
+	"github.com/h4yfans/case-study/domain"
+)
+
+type HealthHandler struct {
+	usecase domain.HealthUsecase
+}
+
+func NewHealthHandler(usecase domain.HealthUsecase, r *mux.Router, auth *middleware.Authentication) {
+	handler := HealthHandler{usecase: usecase}
+
+	auth.Public(r.HandleFunc("/healthz", handler.Live).Methods(http.MethodGet))
+	auth.Public(r.HandleFunc("/readyz", handler.Ready).Methods(http.MethodGet))
+}
+
+func (h *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	respondWithHealth(w, h.usecase.Live(r.Context()))
+}
+
+// Ready answers 503 when any check is down so the instance is taken out of
+// rotation.
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	respondWithHealth(w, h.usecase.Ready(r.Context()))
+}
+
+func respondWithHealth(w http.ResponseWriter, health *domain.HealthResponse) {
+	w.Header().Set("Cache-Control", "no-store")
+
+	code := http.StatusOK
+	if health.Status != domain.HealthStatusUp {
+		code = http.StatusServiceUnavailable
+	}
+	common.RespondWithJSON(w, code, health)
+}
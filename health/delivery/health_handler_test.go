@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/h4yfans/case-study/domain"
+	mocks "github.com/h4yfans/case-study/mocks/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLive(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "/healthz", nil)
+	assert.NoError(t, err)
+
+	mockUCase := new(mocks.HealthUsecase)
+	mockUCase.On("Live", req.Context()).Return(&domain.HealthResponse{Status: domain.HealthStatusUp})
+
+	rec := httptest.NewRecorder()
+	handler := HealthHandler{usecase: mockUCase}
+
+	handler.Live(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+	mockUCase.AssertExpectations(t)
+}
+
+func TestReady(t *testing.T) {
+	t.Run("should return 200", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/readyz", nil)
+		assert.NoError(t, err)
+
+		health := &domain.HealthResponse{
+			Status: domain.HealthStatusUp,
+			Checks: []domain.HealthCheck{{Name: "database", Status: domain.HealthStatusUp, LatencyMS: 1.5}},
+		}
+		mockUCase := new(mocks.HealthUsecase)
+		mockUCase.On("Ready", req.Context()).Return(health)
+
+		rec := httptest.NewRecorder()
+		handler := HealthHandler{usecase: mockUCase}
+
+		handler.Ready(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var body domain.HealthResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, *health, body)
+		mockUCase.AssertExpectations(t)
+	})
+
+	t.Run("should return 503", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/readyz", nil)
+		assert.NoError(t, err)
+
+		mockUCase := new(mocks.HealthUsecase)
+		mockUCase.On("Ready", req.Context()).Return(&domain.HealthResponse{
+			Status: domain.HealthStatusDown,
+			Checks: []domain.HealthCheck{{Name: "shutdown", Status: domain.HealthStatusDown, Error: "Shutting down"}},
+		})
+
+		rec := httptest.NewRecorder()
+		handler := HealthHandler{usecase: mockUCase}
+
+		handler.Ready(rec, req)
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		mockUCase.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/h4yfans/case-study/common"
+	"github.com/h4yfans/case-study/common/db"
+	"github.com/h4yfans/case-study/domain"
+	"github.com/h4yfans/case-study/models"
+)
+
+type HealthRepository struct {
+	db *sql.DB
+}
+
+func NewHealthRepository(db *sql.DB) domain.HealthRepository {
+	return &HealthRepository{
+		db: db,
+	}
+}
+
+func (h *HealthRepository) Ping(ctx context.Context) error {
+	if err := h.db.PingContext(ctx); err != nil {
+		return healthError(err)
+	}
+	return nil
+}
+
+func (h *HealthRepository) MigrationState(ctx context.Context) (int64, bool, error) {
+	migration, err := models.SchemaMigrations().One(ctx, h.db)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, healthError(err)
+	}
+
+	return migration.Version, migration.Dirty, nil
+}
+
+// healthError keeps driver messages, which may name hosts and users, out of
+// the public health endpoints.
+func healthError(err error) error {
+	if db.Classify(err) == db.ClassTimeout {
+		return common.Timeout
+	}
+	return common.ServerError
+}
@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/h4yfans/case-study/domain"
+)
+
+var (
+	errNotMigrated    = errors.New("No migrations have been applied")
+	errDirtyMigration = errors.New("Last migration failed and left the schema dirty")
+	errDraining       = errors.New("Shutting down")
+)
+
+type HealthUsecase struct {
+	repo    domain.HealthRepository
+	timeout time.Duration
+
+	draining int32
+}
+
+// NewHealthUsecase returns a usecase whose readiness checks each give up
+// after timeout.
+func NewHealthUsecase(repo domain.HealthRepository, timeout time.Duration) *HealthUsecase {
+	return &HealthUsecase{
+		repo:    repo,
+		timeout: timeout,
+	}
+}
+
+// Live only tells that the process can still answer requests, so it checks
+// no dependencies: a database outage must not get every instance restarted.
+func (h *HealthUsecase) Live(c context.Context) *domain.HealthResponse {
+	return &domain.HealthResponse{Status: domain.HealthStatusUp}
+}
+
+func (h *HealthUsecase) Ready(c context.Context) *domain.HealthResponse {
+	checks := []domain.HealthCheck{
+		h.check(c, "database", h.database),
+		h.check(c, "migrations", h.migrations),
+		h.check(c, "shutdown", h.shutdown),
+	}
+
+	response := &domain.HealthResponse{Status: domain.HealthStatusUp, Checks: checks}
+	for _, check := range checks {
+		if check.Status != domain.HealthStatusUp {
+			response.Status = domain.HealthStatusDown
+		}
+	}
+	return response
+}
+
+func (h *HealthUsecase) Drain() {
+	atomic.StoreInt32(&h.draining, 1)
+}
+
+type checkFunc func(c context.Context) (map[string]interface{}, error)
+
+// check runs fn under the check timeout and times it.
+func (h *HealthUsecase) check(c context.Context, name string, fn checkFunc) domain.HealthCheck {
+	ctx, cancel := context.WithTimeout(c, h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	details, err := fn(ctx)
+	result := domain.HealthCheck{
+		Name:      name,
+		Status:    domain.HealthStatusUp,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+		Details:   details,
+	}
+	if err != nil {
+		result.Status = domain.HealthStatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
+
+func (h *HealthUsecase) database(c context.Context) (map[string]interface{}, error) {
+	return nil, h.repo.Ping(c)
+}
+
+func (h *HealthUsecase) migrations(c context.Context) (map[string]interface{}, error) {
+	version, dirty, err := h.repo.MigrationState(c)
+	if err != nil {
+		return nil, err
+	}
+
+	details := map[string]interface{}{"version": version, "dirty": dirty}
+	if version == 0 {
+		return details, errNotMigrated
+	}
+	if dirty {
+		return details, errDirtyMigration
+	}
+	return details, nil
+}
+
+func (h *HealthUsecase) shutdown(c context.Context) (map[string]interface{}, error) {
+	if atomic.LoadInt32(&h.draining) == 1 {
+		return nil, errDraining
+	}
+	return nil, nil
+}
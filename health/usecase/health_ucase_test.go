@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/h4yfans/case-study/common"
+	"github.com/h4yfans/case-study/domain"
+	mocks "github.com/h4yfans/case-study/mocks/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func checkStatuses(response *domain.HealthResponse) map[string]string {
+	statuses := make(map[string]string, len(response.Checks))
+	for _, check := range response.Checks {
+		statuses[check.Name] = check.Status
+	}
+	return statuses
+}
+
+func TestLive(t *testing.T) {
+	mockRepo := new(mocks.HealthRepository)
+
+	u := NewHealthUsecase(mockRepo, time.Second)
+	response := u.Live(context.Background())
+	assert.Equal(t, domain.HealthStatusUp, response.Status)
+	assert.Empty(t, response.Checks)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestReady(t *testing.T) {
+	mockRepo := new(mocks.HealthRepository)
+	mockRepo.On("Ping", mock.Anything).Return(nil)
+	mockRepo.On("MigrationState", mock.Anything).Return(int64(12), false, nil)
+
+	u := NewHealthUsecase(mockRepo, time.Second)
+	response := u.Ready(context.Background())
+	assert.Equal(t, domain.HealthStatusUp, response.Status)
+	assert.Equal(t, map[string]string{
+		"database":   domain.HealthStatusUp,
+		"migrations": domain.HealthStatusUp,
+		"shutdown":   domain.HealthStatusUp,
+	}, checkStatuses(response))
+	assert.Equal(t, int64(12), response.Checks[1].Details["version"])
+	mockRepo.AssertExpectations(t)
+}
+
+func TestReadyDatabaseDown(t *testing.T) {
+	mockRepo := new(mocks.HealthRepository)
+	mockRepo.On("Ping", mock.Anything).Return(common.Timeout)
+	mockRepo.On("MigrationState", mock.Anything).Return(int64(0), false, common.Timeout)
+
+	u := NewHealthUsecase(mockRepo, time.Second)
+	response := u.Ready(context.Background())
+	assert.Equal(t, domain.HealthStatusDown, response.Status)
+	assert.Equal(t, domain.HealthStatusDown, response.Checks[0].Status)
+	assert.Equal(t, common.Timeout.Error(), response.Checks[0].Error)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestReadyChecksRunUnderTimeout(t *testing.T) {
+	mockRepo := new(mocks.HealthRepository)
+	mockRepo.On("Ping", mock.MatchedBy(func(c context.Context) bool {
+		_, ok := c.Deadline()
+		return ok
+	})).Return(nil)
+	mockRepo.On("MigrationState", mock.Anything).Return(int64(12), false, nil)
+
+	u := NewHealthUsecase(mockRepo, time.Second)
+	u.Ready(context.Background())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestReadyMigrations(t *testing.T) {
+	t.Run("dirty", func(t *testing.T) {
+		mockRepo := new(mocks.HealthRepository)
+		mockRepo.On("Ping", mock.Anything).Return(nil)
+		mockRepo.On("MigrationState", mock.Anything).Return(int64(12), true, nil)
+
+		u := NewHealthUsecase(mockRepo, time.Second)
+		response := u.Ready(context.Background())
+		assert.Equal(t, domain.HealthStatusDown, response.Status)
+		assert.Equal(t, domain.HealthStatusDown, checkStatuses(response)["migrations"])
+	})
+
+	t.Run("none applied", func(t *testing.T) {
+		mockRepo := new(mocks.HealthRepository)
+		mockRepo.On("Ping", mock.Anything).Return(nil)
+		mockRepo.On("MigrationState", mock.Anything).Return(int64(0), false, nil)
+
+		u := NewHealthUsecase(mockRepo, time.Second)
+		response := u.Ready(context.Background())
+		assert.Equal(t, domain.HealthStatusDown, response.Status)
+		assert.Equal(t, domain.HealthStatusDown, checkStatuses(response)["migrations"])
+	})
+}
+
+func TestReadyWhileDraining(t *testing.T) {
+	mockRepo := new(mocks.HealthRepository)
+	mockRepo.On("Ping", mock.Anything).Return(nil)
+	mockRepo.On("MigrationState", mock.Anything).Return(int64(12), false, nil)
+
+	u := NewHealthUsecase(mockRepo, time.Second)
+	u.Drain()
+	response := u.Ready(context.Background())
+	assert.Equal(t, domain.HealthStatusDown, response.Status)
+	assert.Equal(t, domain.HealthStatusDown, checkStatuses(response)["shutdown"])
+	assert.Equal(t, domain.HealthStatusUp, u.Live(context.Background()).Status)
+}
@@ -1,15 +1,39 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
+	_authDelivery "github.com/h4yfans/case-study/auth/delivery"
+	_authRepo "github.com/h4yfans/case-study/auth/repository"
+	_authUsecase "github.com/h4yfans/case-study/auth/usecase"
+	"github.com/h4yfans/case-study/common"
+	"github.com/h4yfans/case-study/common/breached"
 	"github.com/h4yfans/case-study/common/db"
+	"github.com/h4yfans/case-study/common/emailaddr"
 	"github.com/h4yfans/case-study/common/environment"
+	"github.com/h4yfans/case-study/common/hasher"
+	"github.com/h4yfans/case-study/common/lockout"
 	"github.com/h4yfans/case-study/common/logging"
+	"github.com/h4yfans/case-study/common/metrics"
+	"github.com/h4yfans/case-study/common/middleware"
+	"github.com/h4yfans/case-study/common/notifier"
+	"github.com/h4yfans/case-study/common/password"
+	"github.com/h4yfans/case-study/common/server"
+	"github.com/h4yfans/case-study/common/token"
+	"github.com/h4yfans/case-study/common/totp"
+	"github.com/h4yfans/case-study/common/tracing"
+	_healthDelivery "github.com/h4yfans/case-study/health/delivery"
+	_healthRepo "github.com/h4yfans/case-study/health/repository"
+	_healthUsecase "github.com/h4yfans/case-study/health/usecase"
+	_roleRepo "github.com/h4yfans/case-study/role/repository"
 	_userDelivery "github.com/h4yfans/case-study/user/delivery"
 	_userRepo "github.com/h4yfans/case-study/user/repository"
 	_userUsecase "github.com/h4yfans/case-study/user/usecase"
@@ -17,20 +41,58 @@ import (
 	"go.uber.org/zap"
 )
 
+const tracingShutdownTimeout = time.Second * 5 // 5 Seconds
+
 type Configuration struct {
 	RoutePrefix    string
 	Port           int
+	MetricsPort    int
+	Server         server.Config
 	DB             db.Config
+	Token          token.Config
+	TOTP           totp.Config
+	Lockout        lockout.Config
+	Hasher         hasher.Config
+	Password       password.Config
+	Breached       breached.Config
+	EmailAddress   emailaddr.Config
+	Notifier       notifier.Config
+	Tracing        tracing.Config
 	ContextTimeout time.Duration
+	HealthTimeout  time.Duration
 	Debug          bool
+
+	RequireEmailVerification bool
 }
 
 func main() {
+	if err := run(); err != nil {
+		os.Exit(1)
+	}
+}
+
+// run serves until SIGINT or SIGTERM and returns once in-flight requests have
+// drained, so the deferred cleanup runs before the process exits.
+func run() error {
 	config := Configuration{
 		Port:           environment.Port(),
+		MetricsPort:    environment.MetricsPort(),
+		Server:         environment.Server(),
 		DB:             environment.Database(),
+		Token:          environment.Token(),
+		TOTP:           environment.TOTP(),
+		Lockout:        environment.Lockout(),
+		Hasher:         environment.Hasher(),
+		Password:       environment.Password(),
+		Breached:       environment.Breached(),
+		EmailAddress:   environment.EmailAddress(),
+		Notifier:       environment.Notifier(),
+		Tracing:        environment.Tracing(),
 		ContextTimeout: environment.ContextTimeout(),
+		HealthTimeout:  environment.HealthCheckTimeout(),
 		Debug:          environment.Debug(),
+
+		RequireEmailVerification: environment.RequireEmailVerification(),
 	}
 
 	// Router
@@ -40,29 +102,122 @@ func main() {
 	logging.Initialize()
 	defer logging.Close()
 
+	// Initialize Tracing
+	shutdownTracing := tracing.Initialize(config.Tracing)
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			zap.L().Error("Spans could not be flushed", zap.Error(err))
+		}
+	}()
+
 	// Configure Database
 	boil.DebugMode = environment.BoilDebug()
 	DB := db.Connect(config.DB)
 	db.Migrate(DB, config.DB)
 	defer db.Close(DB)
+	metrics.RegisterDB(DB, config.DB.Name)
 
-	headersOk := handlers.AllowedHeaders([]string{"content-type"})
+	headersOk := handlers.AllowedHeaders([]string{"content-type", "authorization", "x-request-id"})
+	exposedOk := handlers.ExposedHeaders([]string{common.RequestIDHeader})
 	originsOk := handlers.AllowedOrigins([]string{"*"})
 	methodsOk := handlers.AllowedMethods([]string{"GET", "HEAD", "POST", "PUT", "DELETE", "OPTIONS"})
 
 	// Initialize Repositories
 	// -- User --
-	userRepo := _userRepo.NewUserRepository(DB)
+	userRepo := _userRepo.NewTracingUserRepository(_userRepo.NewUserRepository(DB))
+	// -- Role --
+	roleRepo := _roleRepo.NewRoleRepository(DB)
+	// -- Health --
+	healthRepo := _healthRepo.NewHealthRepository(DB)
+	// -- Auth --
+	refreshTokenRepo := _authRepo.NewRefreshTokenRepository(DB)
+	userTokenRepo := _authRepo.NewUserTokenRepository(DB)
+	recoveryCodeRepo := _authRepo.NewRecoveryCodeRepository(DB)
+	loginAttemptRepo := _authRepo.NewLoginAttemptRepository(DB)
+
+	// Initialize Token Managers
+	tokenManager := token.NewManager(config.Token)
+	totpManager := totp.NewManager(config.TOTP)
+
+	// Initialize Lockout Policy
+	lockoutPolicy := lockout.NewPolicy(config.Lockout)
+
+	// Initialize Password Checks
+	passwordHasher := hasher.New(config.Hasher)
+	passwordPolicy := password.NewPolicy(config.Password)
+	passwordScreener := breached.New(config.Breached)
+
+	// Initialize Email Normalization
+	emailNormalizer := emailaddr.NewNormalizer(config.EmailAddress)
+
+	// Initialize Notifier
+	userNotifier := notifier.New(config.Notifier)
 
 	// Initialize Usecase
 	// -- User --
-	userUsecase := _userUsecase.NewUserUsecase(userRepo)
+	userUsecase := _userUsecase.NewMetricsUserUsecase(_userUsecase.NewTracingUserUsecase(_userUsecase.NewUserUsecase(userRepo, userTokenRepo, loginAttemptRepo, userNotifier, tokenManager, passwordHasher, passwordPolicy, passwordScreener, emailNormalizer)))
+	// -- Health --
+	healthUsecase := _healthUsecase.NewHealthUsecase(healthRepo, config.HealthTimeout)
+	// -- Auth --
+	authUsecase := _authUsecase.NewAuthUsecase(userRepo, refreshTokenRepo, roleRepo, userTokenRepo, recoveryCodeRepo, loginAttemptRepo, passwordHasher, passwordPolicy, passwordScreener, userNotifier, tokenManager, totpManager, lockoutPolicy, emailNormalizer, config.RequireEmailVerification)
+
+	// Initialize Middleware
+	authentication := middleware.NewAuthentication(middleware.NewBearerAuthenticator(authUsecase))
+	rootRouter.Use(authentication.Middleware)
 
 	// Initialize Handler
-	_userDelivery.NewUserHandler(userUsecase, rootRouter)
+	_userDelivery.NewUserHandler(userUsecase, rootRouter, authentication)
+	_authDelivery.NewAuthHandler(authUsecase, rootRouter, authentication)
+	_healthDelivery.NewHealthHandler(healthUsecase, rootRouter, authentication)
+
+	// The router only runs its middleware for matched routes, so tracing,
+	// logging and metrics wrap it from outside to also see 404s, 405s and CORS
+	// preflights.
+	handler := handlers.CORS(originsOk, headersOk, exposedOk, methodsOk)(rootRouter)
+	handler = metrics.Middleware(rootRouter)(handler)
+	handler = middleware.RequestLogging(rootRouter)(handler)
+	handler = tracing.Middleware(rootRouter, config.Tracing.ServiceName)(handler)
 
 	// Serve
-	http.Handle("/", rootRouter)
+	srv := &http.Server{
+		Addr:    fmt.Sprintf(":%v", config.Port),
+		Handler: handler,
+	}
+	// Metrics are served on an internal port only.
+	metricsRouter := mux.NewRouter()
+	metricsRouter.Handle("/metrics", metrics.Handler()).Methods(http.MethodGet)
+	metricsSrv := &http.Server{
+		Addr:    fmt.Sprintf(":%v", config.MetricsPort),
+		Handler: metricsRouter,
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// The metrics server keeps serving while the public one drains and a
+	// failure of either stops both.
+	metricsErrs := make(chan error, 1)
+	go func() {
+		zap.S().Infof("Starting metrics listening %v", config.MetricsPort)
+		err := server.Run(ctx, metricsSrv, config.Server, nil)
+		if err != nil {
+			zap.L().Error("Metrics server stopped", zap.Error(err))
+			stop()
+		}
+		metricsErrs <- err
+	}()
+
 	zap.S().Infof("Starting listening %v", config.Port)
-	zap.S().Fatal(http.ListenAndServe(fmt.Sprintf(":%v", config.Port), handlers.CORS(originsOk, headersOk, methodsOk)(rootRouter)))
+	err := server.Run(ctx, srv, config.Server, healthUsecase.Drain)
+	stop()
+	if metricsErr := <-metricsErrs; err == nil {
+		err = metricsErr
+	}
+	if err != nil {
+		zap.L().Error("Server stopped", zap.Error(err))
+		return err
+	}
+	zap.L().Info("Server stopped")
+	return nil
 }
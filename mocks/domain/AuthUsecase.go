@@ -0,0 +1,196 @@
+// Code generated by mockery v2.9.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	domain "github.com/h4yfans/case-study/domain"
+)
+
+// AuthUsecase is an autogenerated mock type for the AuthUsecase type
+type AuthUsecase struct {
+	mock.Mock
+}
+
+// Authenticate provides a mock function with given fields: c, accessToken
+func (_m *AuthUsecase) Authenticate(c context.Context, accessToken string) (*domain.Principal, error) {
+	ret := _m.Called(c, accessToken)
+
+	var r0 *domain.Principal
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.Principal); ok {
+		r0 = rf(c, accessToken)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Principal)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(c, accessToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ConfirmTOTP provides a mock function with given fields: c, principal, code
+func (_m *AuthUsecase) ConfirmTOTP(c context.Context, principal *domain.Principal, code string) (*domain.RecoveryCodesResponse, error) {
+	ret := _m.Called(c, principal, code)
+
+	var r0 *domain.RecoveryCodesResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Principal, string) *domain.RecoveryCodesResponse); ok {
+		r0 = rf(c, principal, code)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.RecoveryCodesResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.Principal, string) error); ok {
+		r1 = rf(c, principal, code)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// EnrollTOTP provides a mock function with given fields: c, principal
+func (_m *AuthUsecase) EnrollTOTP(c context.Context, principal *domain.Principal) (*domain.TOTPEnrollmentResponse, error) {
+	ret := _m.Called(c, principal)
+
+	var r0 *domain.TOTPEnrollmentResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Principal) *domain.TOTPEnrollmentResponse); ok {
+		r0 = rf(c, principal)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.TOTPEnrollmentResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.Principal) error); ok {
+		r1 = rf(c, principal)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ForgotPassword provides a mock function with given fields: c, email
+func (_m *AuthUsecase) ForgotPassword(c context.Context, email string) error {
+	ret := _m.Called(c, email)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(c, email)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Login provides a mock function with given fields: c, credentials
+func (_m *AuthUsecase) Login(c context.Context, credentials *domain.LoginRequest) (*domain.TokenResponse, error) {
+	ret := _m.Called(c, credentials)
+
+	var r0 *domain.TokenResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.LoginRequest) *domain.TokenResponse); ok {
+		r0 = rf(c, credentials)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.TokenResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.LoginRequest) error); ok {
+		r1 = rf(c, credentials)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LoginTwoFactor provides a mock function with given fields: c, request
+func (_m *AuthUsecase) LoginTwoFactor(c context.Context, request *domain.TwoFactorLoginRequest) (*domain.TokenResponse, error) {
+	ret := _m.Called(c, request)
+
+	var r0 *domain.TokenResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.TwoFactorLoginRequest) *domain.TokenResponse); ok {
+		r0 = rf(c, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.TokenResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.TwoFactorLoginRequest) error); ok {
+		r1 = rf(c, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Logout provides a mock function with given fields: c, refreshToken
+func (_m *AuthUsecase) Logout(c context.Context, refreshToken string) error {
+	ret := _m.Called(c, refreshToken)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(c, refreshToken)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Refresh provides a mock function with given fields: c, refreshToken
+func (_m *AuthUsecase) Refresh(c context.Context, refreshToken string) (*domain.TokenResponse, error) {
+	ret := _m.Called(c, refreshToken)
+
+	var r0 *domain.TokenResponse
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.TokenResponse); ok {
+		r0 = rf(c, refreshToken)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.TokenResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(c, refreshToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ResetPassword provides a mock function with given fields: c, request
+func (_m *AuthUsecase) ResetPassword(c context.Context, request *domain.PasswordResetRequest) error {
+	ret := _m.Called(c, request)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.PasswordResetRequest) error); ok {
+		r0 = rf(c, request)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
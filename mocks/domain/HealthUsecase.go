@@ -0,0 +1,53 @@
+// Code generated by mockery v2.9.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	domain "github.com/h4yfans/case-study/domain"
+)
+
+// HealthUsecase is an autogenerated mock type for the HealthUsecase type
+type HealthUsecase struct {
+	mock.Mock
+}
+
+// Drain provides a mock function with given fields:
+func (_m *HealthUsecase) Drain() {
+	_m.Called()
+}
+
+// Live provides a mock function with given fields: c
+func (_m *HealthUsecase) Live(c context.Context) *domain.HealthResponse {
+	ret := _m.Called(c)
+
+	var r0 *domain.HealthResponse
+	if rf, ok := ret.Get(0).(func(context.Context) *domain.HealthResponse); ok {
+		r0 = rf(c)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.HealthResponse)
+		}
+	}
+
+	return r0
+}
+
+// Ready provides a mock function with given fields: c
+func (_m *HealthUsecase) Ready(c context.Context) *domain.HealthResponse {
+	ret := _m.Called(c)
+
+	var r0 *domain.HealthResponse
+	if rf, ok := ret.Get(0).(func(context.Context) *domain.HealthResponse); ok {
+		r0 = rf(c)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.HealthResponse)
+		}
+	}
+
+	return r0
+}
@@ -0,0 +1,89 @@
+// Code generated by mockery v2.9.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	time "time"
+
+	mock "github.com/stretchr/testify/mock"
+
+	models "github.com/h4yfans/case-study/models"
+)
+
+// LoginAttemptRepository is an autogenerated mock type for the LoginAttemptRepository type
+type LoginAttemptRepository struct {
+	mock.Mock
+}
+
+// Block provides a mock function with given fields: c, key, until, locked
+func (_m *LoginAttemptRepository) Block(c context.Context, key string, until time.Time, locked bool) error {
+	ret := _m.Called(c, key, until, locked)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, bool) error); ok {
+		r0 = rf(c, key, until, locked)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Get provides a mock function with given fields: c, key
+func (_m *LoginAttemptRepository) Get(c context.Context, key string) (*models.LoginAttempt, error) {
+	ret := _m.Called(c, key)
+
+	var r0 *models.LoginAttempt
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.LoginAttempt); ok {
+		r0 = rf(c, key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.LoginAttempt)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(c, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RecordFailure provides a mock function with given fields: c, key, window
+func (_m *LoginAttemptRepository) RecordFailure(c context.Context, key string, window time.Duration) (int, error) {
+	ret := _m.Called(c, key, window)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Duration) int); ok {
+		r0 = rf(c, key, window)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Duration) error); ok {
+		r1 = rf(c, key, window)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Reset provides a mock function with given fields: c, key
+func (_m *LoginAttemptRepository) Reset(c context.Context, key string) error {
+	ret := _m.Called(c, key)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(c, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -0,0 +1,30 @@
+// Code generated by mockery v2.9.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	domain "github.com/h4yfans/case-study/domain"
+)
+
+// Notifier is an autogenerated mock type for the Notifier type
+type Notifier struct {
+	mock.Mock
+}
+
+// Send provides a mock function with given fields: c, notification
+func (_m *Notifier) Send(c context.Context, notification *domain.Notification) error {
+	ret := _m.Called(c, notification)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Notification) error); ok {
+		r0 = rf(c, notification)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -0,0 +1,35 @@
+// Code generated by mockery v2.9.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// PasswordScreener is an autogenerated mock type for the PasswordScreener type
+type PasswordScreener struct {
+	mock.Mock
+}
+
+// Breached provides a mock function with given fields: c, password
+func (_m *PasswordScreener) Breached(c context.Context, password string) (bool, error) {
+	ret := _m.Called(c, password)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(c, password)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(c, password)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -0,0 +1,42 @@
+// Code generated by mockery v2.9.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// RecoveryCodeRepository is an autogenerated mock type for the RecoveryCodeRepository type
+type RecoveryCodeRepository struct {
+	mock.Mock
+}
+
+// Replace provides a mock function with given fields: c, userID, hashes
+func (_m *RecoveryCodeRepository) Replace(c context.Context, userID int, hashes []string) error {
+	ret := _m.Called(c, userID, hashes)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, []string) error); ok {
+		r0 = rf(c, userID, hashes)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Use provides a mock function with given fields: c, userID, hash
+func (_m *RecoveryCodeRepository) Use(c context.Context, userID int, hash string) error {
+	ret := _m.Called(c, userID, hash)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) error); ok {
+		r0 = rf(c, userID, hash)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
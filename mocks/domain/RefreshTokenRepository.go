@@ -0,0 +1,104 @@
+// Code generated by mockery v2.9.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	models "github.com/h4yfans/case-study/models"
+)
+
+// RefreshTokenRepository is an autogenerated mock type for the RefreshTokenRepository type
+type RefreshTokenRepository struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: c, token
+func (_m *RefreshTokenRepository) Create(c context.Context, token *models.RefreshToken) (*models.RefreshToken, error) {
+	ret := _m.Called(c, token)
+
+	var r0 *models.RefreshToken
+	if rf, ok := ret.Get(0).(func(context.Context, *models.RefreshToken) *models.RefreshToken); ok {
+		r0 = rf(c, token)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.RefreshToken)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.RefreshToken) error); ok {
+		r1 = rf(c, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByHash provides a mock function with given fields: c, hash
+func (_m *RefreshTokenRepository) GetByHash(c context.Context, hash string) (*models.RefreshToken, error) {
+	ret := _m.Called(c, hash)
+
+	var r0 *models.RefreshToken
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.RefreshToken); ok {
+		r0 = rf(c, hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.RefreshToken)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(c, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkUsed provides a mock function with given fields: c, id
+func (_m *RefreshTokenRepository) MarkUsed(c context.Context, id int) error {
+	ret := _m.Called(c, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(c, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RevokeAllForUser provides a mock function with given fields: c, userID
+func (_m *RefreshTokenRepository) RevokeAllForUser(c context.Context, userID int) error {
+	ret := _m.Called(c, userID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(c, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RevokeFamily provides a mock function with given fields: c, familyID
+func (_m *RefreshTokenRepository) RevokeFamily(c context.Context, familyID string) error {
+	ret := _m.Called(c, familyID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(c, familyID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
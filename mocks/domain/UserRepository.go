@@ -7,6 +7,7 @@ import (
 
 	mock "github.com/stretchr/testify/mock"
 
+	domain "github.com/h4yfans/case-study/domain"
 	models "github.com/h4yfans/case-study/models"
 )
 
@@ -15,6 +16,41 @@ type UserRepository struct {
 	mock.Mock
 }
 
+// ChangeEmail provides a mock function with given fields: c, id, email
+func (_m *UserRepository) ChangeEmail(c context.Context, id int, email string) error {
+	ret := _m.Called(c, id, email)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) error); ok {
+		r0 = rf(c, id, email)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CountUsers provides a mock function with given fields: c, filters
+func (_m *UserRepository) CountUsers(c context.Context, filters []domain.UserFilter) (int64, error) {
+	ret := _m.Called(c, filters)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, []domain.UserFilter) int64); ok {
+		r0 = rf(c, filters)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []domain.UserFilter) error); ok {
+		r1 = rf(c, filters)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Create provides a mock function with given fields: c, user
 func (_m *UserRepository) Create(c context.Context, user *models.User) (*models.User, error) {
 	ret := _m.Called(c, user)
@@ -38,8 +74,22 @@ func (_m *UserRepository) Create(c context.Context, user *models.User) (*models.
 	return r0, r1
 }
 
-// Delete provides a mock function with given fields: c, id
-func (_m *UserRepository) Delete(c context.Context, id int) error {
+// Delete provides a mock function with given fields: c, user
+func (_m *UserRepository) Delete(c context.Context, user *models.User) error {
+	ret := _m.Called(c, user)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User) error); ok {
+		r0 = rf(c, user)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// EnableTOTP provides a mock function with given fields: c, id
+func (_m *UserRepository) EnableTOTP(c context.Context, id int) error {
 	ret := _m.Called(c, id)
 
 	var r0 error
@@ -52,13 +102,13 @@ func (_m *UserRepository) Delete(c context.Context, id int) error {
 	return r0
 }
 
-// GetAllUser provides a mock function with given fields: c
-func (_m *UserRepository) GetAllUser(c context.Context) (models.UserSlice, error) {
-	ret := _m.Called(c)
+// GetAllUser provides a mock function with given fields: c, query
+func (_m *UserRepository) GetAllUser(c context.Context, query *domain.UserListQuery) (models.UserSlice, error) {
+	ret := _m.Called(c, query)
 
 	var r0 models.UserSlice
-	if rf, ok := ret.Get(0).(func(context.Context) models.UserSlice); ok {
-		r0 = rf(c)
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.UserListQuery) models.UserSlice); ok {
+		r0 = rf(c, query)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(models.UserSlice)
@@ -66,8 +116,31 @@ func (_m *UserRepository) GetAllUser(c context.Context) (models.UserSlice, error
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = rf(c)
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.UserListQuery) error); ok {
+		r1 = rf(c, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByEmail provides a mock function with given fields: c, email
+func (_m *UserRepository) GetByEmail(c context.Context, email string) (*models.User, error) {
+	ret := _m.Called(c, email)
+
+	var r0 *models.User
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.User); ok {
+		r0 = rf(c, email)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(c, email)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -98,13 +171,106 @@ func (_m *UserRepository) GetByID(c context.Context, id int) (*models.User, erro
 	return r0, r1
 }
 
-// Update provides a mock function with given fields: c, user
-func (_m *UserRepository) Update(c context.Context, user *models.User) (*models.User, error) {
-	ret := _m.Called(c, user)
+// MarkEmailVerified provides a mock function with given fields: c, id
+func (_m *UserRepository) MarkEmailVerified(c context.Context, id int) error {
+	ret := _m.Called(c, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(c, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Purge provides a mock function with given fields: c, id
+func (_m *UserRepository) Purge(c context.Context, id int) error {
+	ret := _m.Called(c, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(c, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Restore provides a mock function with given fields: c, id
+func (_m *UserRepository) Restore(c context.Context, id int) error {
+	ret := _m.Called(c, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(c, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Search provides a mock function with given fields: c, query, limit
+func (_m *UserRepository) Search(c context.Context, query string, limit int) ([]domain.UserSearchHit, error) {
+	ret := _m.Called(c, query, limit)
+
+	var r0 []domain.UserSearchHit
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) []domain.UserSearchHit); ok {
+		r0 = rf(c, query, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.UserSearchHit)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(c, query, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetPendingEmail provides a mock function with given fields: c, id, email
+func (_m *UserRepository) SetPendingEmail(c context.Context, id int, email string) error {
+	ret := _m.Called(c, id, email)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) error); ok {
+		r0 = rf(c, id, email)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetTOTPSecret provides a mock function with given fields: c, id, secret
+func (_m *UserRepository) SetTOTPSecret(c context.Context, id int, secret string) error {
+	ret := _m.Called(c, id, secret)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) error); ok {
+		r0 = rf(c, id, secret)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: c, user, columns
+func (_m *UserRepository) Update(c context.Context, user *models.User, columns []string) (*models.User, error) {
+	ret := _m.Called(c, user, columns)
 
 	var r0 *models.User
-	if rf, ok := ret.Get(0).(func(context.Context, *models.User) *models.User); ok {
-		r0 = rf(c, user)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User, []string) *models.User); ok {
+		r0 = rf(c, user, columns)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.User)
@@ -112,11 +278,39 @@ func (_m *UserRepository) Update(c context.Context, user *models.User) (*models.
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, *models.User) error); ok {
-		r1 = rf(c, user)
+	if rf, ok := ret.Get(1).(func(context.Context, *models.User, []string) error); ok {
+		r1 = rf(c, user, columns)
 	} else {
 		r1 = ret.Error(1)
 	}
 
 	return r0, r1
 }
+
+// UpdatePassword provides a mock function with given fields: c, id, password
+func (_m *UserRepository) UpdatePassword(c context.Context, id int, password string) error {
+	ret := _m.Called(c, id, password)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) error); ok {
+		r0 = rf(c, id, password)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UseTOTPCounter provides a mock function with given fields: c, id, counter
+func (_m *UserRepository) UseTOTPCounter(c context.Context, id int, counter int64) error {
+	ret := _m.Called(c, id, counter)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int64) error); ok {
+		r0 = rf(c, id, counter)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -0,0 +1,90 @@
+// Code generated by mockery v2.9.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	models "github.com/h4yfans/case-study/models"
+)
+
+// UserTokenRepository is an autogenerated mock type for the UserTokenRepository type
+type UserTokenRepository struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: c, token
+func (_m *UserTokenRepository) Create(c context.Context, token *models.UserToken) (*models.UserToken, error) {
+	ret := _m.Called(c, token)
+
+	var r0 *models.UserToken
+	if rf, ok := ret.Get(0).(func(context.Context, *models.UserToken) *models.UserToken); ok {
+		r0 = rf(c, token)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.UserToken)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.UserToken) error); ok {
+		r1 = rf(c, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByHash provides a mock function with given fields: c, purpose, hash
+func (_m *UserTokenRepository) GetByHash(c context.Context, purpose string, hash string) (*models.UserToken, error) {
+	ret := _m.Called(c, purpose, hash)
+
+	var r0 *models.UserToken
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *models.UserToken); ok {
+		r0 = rf(c, purpose, hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.UserToken)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(c, purpose, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InvalidateAll provides a mock function with given fields: c, userID, purpose
+func (_m *UserTokenRepository) InvalidateAll(c context.Context, userID int, purpose string) error {
+	ret := _m.Called(c, userID, purpose)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) error); ok {
+		r0 = rf(c, userID, purpose)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MarkUsed provides a mock function with given fields: c, id
+func (_m *UserTokenRepository) MarkUsed(c context.Context, id int) error {
+	ret := _m.Called(c, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(c, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -5,9 +5,10 @@ package mocks
 import (
 	context "context"
 
-	domain "github.com/h4yfans/case-study/domain"
 	mock "github.com/stretchr/testify/mock"
 
+	patch "github.com/h4yfans/case-study/common/patch"
+	domain "github.com/h4yfans/case-study/domain"
 	models "github.com/h4yfans/case-study/models"
 )
 
@@ -16,6 +17,20 @@ type UserUsecase struct {
 	mock.Mock
 }
 
+// ConfirmEmailChange provides a mock function with given fields: c, token
+func (_m *UserUsecase) ConfirmEmailChange(c context.Context, token string) error {
+	ret := _m.Called(c, token)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(c, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Create provides a mock function with given fields: c, user
 func (_m *UserUsecase) Create(c context.Context, user *models.User) (*domain.UserResponse, error) {
 	ret := _m.Called(c, user)
@@ -39,13 +54,13 @@ func (_m *UserUsecase) Create(c context.Context, user *models.User) (*domain.Use
 	return r0, r1
 }
 
-// Delete provides a mock function with given fields: c, id
-func (_m *UserUsecase) Delete(c context.Context, id int) error {
-	ret := _m.Called(c, id)
+// Delete provides a mock function with given fields: c, principal, id, ifMatch
+func (_m *UserUsecase) Delete(c context.Context, principal *domain.Principal, id int, ifMatch string) error {
+	ret := _m.Called(c, principal, id, ifMatch)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
-		r0 = rf(c, id)
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Principal, int, string) error); ok {
+		r0 = rf(c, principal, id, ifMatch)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -53,22 +68,22 @@ func (_m *UserUsecase) Delete(c context.Context, id int) error {
 	return r0
 }
 
-// GetAllUser provides a mock function with given fields: c
-func (_m *UserUsecase) GetAllUser(c context.Context) ([]domain.UserResponse, error) {
-	ret := _m.Called(c)
+// GetAllUser provides a mock function with given fields: c, principal, params
+func (_m *UserUsecase) GetAllUser(c context.Context, principal *domain.Principal, params *domain.UserListParams) (*domain.UserListResponse, error) {
+	ret := _m.Called(c, principal, params)
 
-	var r0 []domain.UserResponse
-	if rf, ok := ret.Get(0).(func(context.Context) []domain.UserResponse); ok {
-		r0 = rf(c)
+	var r0 *domain.UserListResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Principal, *domain.UserListParams) *domain.UserListResponse); ok {
+		r0 = rf(c, principal, params)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]domain.UserResponse)
+			r0 = ret.Get(0).(*domain.UserListResponse)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = rf(c)
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.Principal, *domain.UserListParams) error); ok {
+		r1 = rf(c, principal, params)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -76,13 +91,13 @@ func (_m *UserUsecase) GetAllUser(c context.Context) ([]domain.UserResponse, err
 	return r0, r1
 }
 
-// GetByID provides a mock function with given fields: c, id
-func (_m *UserUsecase) GetByID(c context.Context, id int) (*domain.UserResponse, error) {
-	ret := _m.Called(c, id)
+// GetByID provides a mock function with given fields: c, principal, id
+func (_m *UserUsecase) GetByID(c context.Context, principal *domain.Principal, id int) (*domain.UserResponse, error) {
+	ret := _m.Called(c, principal, id)
 
 	var r0 *domain.UserResponse
-	if rf, ok := ret.Get(0).(func(context.Context, int) *domain.UserResponse); ok {
-		r0 = rf(c, id)
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Principal, int) *domain.UserResponse); ok {
+		r0 = rf(c, principal, id)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*domain.UserResponse)
@@ -90,8 +105,8 @@ func (_m *UserUsecase) GetByID(c context.Context, id int) (*domain.UserResponse,
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
-		r1 = rf(c, id)
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.Principal, int) error); ok {
+		r1 = rf(c, principal, id)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -99,13 +114,106 @@ func (_m *UserUsecase) GetByID(c context.Context, id int) (*domain.UserResponse,
 	return r0, r1
 }
 
-// Update provides a mock function with given fields: c, user
-func (_m *UserUsecase) Update(c context.Context, user *models.User) (*domain.UserResponse, error) {
-	ret := _m.Called(c, user)
+// Purge provides a mock function with given fields: c, principal, id
+func (_m *UserUsecase) Purge(c context.Context, principal *domain.Principal, id int) error {
+	ret := _m.Called(c, principal, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Principal, int) error); ok {
+		r0 = rf(c, principal, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RequestEmailChange provides a mock function with given fields: c, principal, id, email
+func (_m *UserUsecase) RequestEmailChange(c context.Context, principal *domain.Principal, id int, email string) error {
+	ret := _m.Called(c, principal, id, email)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Principal, int, string) error); ok {
+		r0 = rf(c, principal, id, email)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ResendVerification provides a mock function with given fields: c, email
+func (_m *UserUsecase) ResendVerification(c context.Context, email string) error {
+	ret := _m.Called(c, email)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(c, email)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Restore provides a mock function with given fields: c, principal, id
+func (_m *UserUsecase) Restore(c context.Context, principal *domain.Principal, id int) error {
+	ret := _m.Called(c, principal, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Principal, int) error); ok {
+		r0 = rf(c, principal, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Search provides a mock function with given fields: c, principal, params
+func (_m *UserUsecase) Search(c context.Context, principal *domain.Principal, params *domain.UserSearchParams) (*domain.UserSearchResponse, error) {
+	ret := _m.Called(c, principal, params)
+
+	var r0 *domain.UserSearchResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Principal, *domain.UserSearchParams) *domain.UserSearchResponse); ok {
+		r0 = rf(c, principal, params)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.UserSearchResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.Principal, *domain.UserSearchParams) error); ok {
+		r1 = rf(c, principal, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Unlock provides a mock function with given fields: c, principal, id
+func (_m *UserUsecase) Unlock(c context.Context, principal *domain.Principal, id int) error {
+	ret := _m.Called(c, principal, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Principal, int) error); ok {
+		r0 = rf(c, principal, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: c, principal, id, changes, ifMatch
+func (_m *UserUsecase) Update(c context.Context, principal *domain.Principal, id int, changes patch.Patch, ifMatch string) (*domain.UserResponse, error) {
+	ret := _m.Called(c, principal, id, changes, ifMatch)
 
 	var r0 *domain.UserResponse
-	if rf, ok := ret.Get(0).(func(context.Context, *models.User) *domain.UserResponse); ok {
-		r0 = rf(c, user)
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Principal, int, patch.Patch, string) *domain.UserResponse); ok {
+		r0 = rf(c, principal, id, changes, ifMatch)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*domain.UserResponse)
@@ -113,11 +221,25 @@ func (_m *UserUsecase) Update(c context.Context, user *models.User) (*domain.Use
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, *models.User) error); ok {
-		r1 = rf(c, user)
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.Principal, int, patch.Patch, string) error); ok {
+		r1 = rf(c, principal, id, changes, ifMatch)
 	} else {
 		r1 = ret.Error(1)
 	}
 
 	return r0, r1
 }
+
+// VerifyEmail provides a mock function with given fields: c, token
+func (_m *UserUsecase) VerifyEmail(c context.Context, token string) error {
+	ret := _m.Called(c, token)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(c, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -4,9 +4,23 @@
 package models
 
 var TableNames = struct {
+	LoginAttempts    string
+	Permissions      string
+	RecoveryCodes    string
+	RefreshTokens    string
+	RolePermissions  string
+	Roles            string
 	SchemaMigrations string
+	UserTokens       string
 	Users            string
 }{
+	LoginAttempts:    "login_attempts",
+	Permissions:      "permissions",
+	RecoveryCodes:    "recovery_codes",
+	RefreshTokens:    "refresh_tokens",
+	RolePermissions:  "role_permissions",
+	Roles:            "roles",
 	SchemaMigrations: "schema_migrations",
+	UserTokens:       "user_tokens",
 	Users:            "users",
 }
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/h4yfans/case-study/common"
+	"github.com/h4yfans/case-study/common/db"
+	"github.com/h4yfans/case-study/domain"
+	"github.com/h4yfans/case-study/models"
+)
+
+type RoleRepository struct {
+	db *sql.DB
+}
+
+func NewRoleRepository(db *sql.DB) domain.RoleRepository {
+	return &RoleRepository{
+		db: db,
+	}
+}
+
+func (r *RoleRepository) GetPermissions(ctx context.Context, role string) ([]string, error) {
+	roleData, err := models.FindRole(ctx, r.db, role)
+	if err == sql.ErrNoRows {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, roleError(err)
+	}
+
+	permissions, err := roleData.Permissions().All(ctx, r.db)
+	if err != nil {
+		return nil, roleError(err)
+	}
+
+	names := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		names = append(names, permission.Name)
+	}
+
+	return names, nil
+}
+
+func roleError(err error) error {
+	switch db.Classify(err) {
+	case db.ClassConflict:
+		return common.Conflict
+	case db.ClassTimeout:
+		return common.Timeout
+	}
+	return common.ServerError
+}
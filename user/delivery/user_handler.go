@@ -1,13 +1,21 @@
 package handler
 
 import (
-	"context"
 	"encoding/json"
+	"io/ioutil"
+	"mime"
 	"net/http"
+	"net/url"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/h4yfans/case-study/common"
+	"github.com/h4yfans/case-study/common/etag"
+	"github.com/h4yfans/case-study/common/middleware"
+	"github.com/h4yfans/case-study/common/pagination"
+	"github.com/h4yfans/case-study/common/patch"
 	"github.com/h4yfans/case-study/domain"
 	"github.com/h4yfans/case-study/models"
 )
@@ -16,10 +24,17 @@ type UserHandler struct {
 	usecase domain.UserUsecase
 }
 
-func NewUserHandler(usecase domain.UserUsecase, r *mux.Router) {
+func NewUserHandler(usecase domain.UserUsecase, r *mux.Router, auth *middleware.Authentication) {
 	handler := UserHandler{usecase: usecase}
 
-	r.HandleFunc("/users", handler.Create).Methods(http.MethodPut)
+	auth.Public(r.HandleFunc("/users", handler.Create).Methods(http.MethodPut))
+	auth.Public(r.HandleFunc("/users/verify-email", handler.VerifyEmail).Methods(http.MethodPost))
+	auth.Public(r.HandleFunc("/users/verify-email/resend", handler.ResendVerification).Methods(http.MethodPost))
+	auth.Public(r.HandleFunc("/users/email/confirm", handler.ConfirmEmailChange).Methods(http.MethodPost))
+	r.HandleFunc("/users/search", handler.Search).Methods(http.MethodGet)
+	r.HandleFunc("/users/{id}/email", handler.RequestEmailChange).Methods(http.MethodPost)
+	r.HandleFunc("/users/{id}/restore", handler.Restore).Methods(http.MethodPost)
+	r.HandleFunc("/users/{id}/unlock", handler.Unlock).Methods(http.MethodPost)
 	r.HandleFunc("/users/{id}", handler.Update).Methods(http.MethodPatch)
 	r.HandleFunc("/users/{id}", handler.Delete).Methods(http.MethodDelete)
 	r.HandleFunc("/users/{id}", handler.GetByID).Methods(http.MethodGet)
@@ -27,16 +42,21 @@ func NewUserHandler(usecase domain.UserUsecase, r *mux.Router) {
 }
 
 func (u *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
-	var user models.User
-	err := json.NewDecoder(r.Body).Decode(&user)
+	var signup domain.SignupRequest
+	err := json.NewDecoder(r.Body).Decode(&signup)
 	if err != nil {
 		common.RespondWithJSON(w, common.GetStatusCode(err), common.ResponseError{Error: err.Error()})
 		return
 	}
 
+	user := models.User{
+		Name:     signup.Name,
+		Email:    signup.Email,
+		Password: signup.Password,
+	}
 	userData, err := u.usecase.Create(r.Context(), &user)
 	if err != nil {
-		common.RespondWithJSON(w, common.GetStatusCode(err), common.ResponseError{Error: err.Error()})
+		common.RespondWithJSON(w, common.GetStatusCode(err), common.NewResponseError(err))
 		return
 	}
 
@@ -44,28 +64,46 @@ func (u *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
 	return
 }
 
+// Update takes an RFC 7396 JSON Merge Patch, also when sent as plain JSON,
+// or an RFC 6902 JSON Patch.
 func (u *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
-	var user models.User
-	err := json.NewDecoder(r.Body).Decode(&user)
+	vars := mux.Vars(r)
+	userID, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		common.RespondWithJSON(w, common.GetStatusCode(err), common.ResponseError{Error: err.Error()})
+		common.RespondWithJSON(w, http.StatusBadRequest, common.ResponseError{Error: common.BadRequest.Error()})
 		return
 	}
 
-	vars := mux.Vars(r)
-	userID, err := strconv.Atoi(vars["id"])
-	user.ID = userID
+	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		common.RespondWithJSON(w, common.GetStatusCode(err), common.ResponseError{Error: err.Error()})
+		common.RespondWithJSON(w, http.StatusBadRequest, common.ResponseError{Error: common.BadRequest.Error()})
+		return
+	}
 
+	var changes patch.Patch
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case patch.JSONPatchType:
+		changes, err = patch.NewJSONPatch(body)
+	case patch.MergePatchType, "application/json", "":
+		changes, err = patch.NewMergePatch(body)
+	default:
+		common.RespondWithJSON(w, http.StatusUnsupportedMediaType, common.ResponseError{Error: common.UnsupportedMediaType.Error()})
+		return
+	}
+	if err != nil {
+		common.RespondWithJSON(w, http.StatusBadRequest, common.ResponseError{Error: common.BadRequest.Error()})
+		return
 	}
 
-	userData, err := u.usecase.Update(context.Background(), &user)
+	principal, _ := middleware.PrincipalFromContext(r.Context())
+	userData, err := u.usecase.Update(r.Context(), principal, userID, changes, r.Header.Get("If-Match"))
 	if err != nil {
-		common.RespondWithJSON(w, common.GetStatusCode(err), common.ResponseError{Error: err.Error()})
+		common.RespondWithJSON(w, common.GetStatusCode(err), common.NewResponseError(err))
 		return
 	}
 
+	w.Header().Set("ETag", etag.FromTime(userData.UpdatedAt))
 	common.RespondWithJSON(w, http.StatusOK, userData)
 	return
 }
@@ -73,12 +111,42 @@ func (u *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
 func (u *UserHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	userID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		common.RespondWithJSON(w, http.StatusBadRequest, common.ResponseError{Error: common.BadRequest.Error()})
+		return
+	}
+
+	principal, _ := middleware.PrincipalFromContext(r.Context())
+	if r.URL.Query().Get("purge") == "true" {
+		// A purge also removes soft deleted users, which have no version
+		// left to match.
+		if r.Header.Get("If-Match") != "" {
+			common.RespondWithJSON(w, http.StatusBadRequest, common.ResponseError{Error: common.BadRequest.Error()})
+			return
+		}
+		err = u.usecase.Purge(r.Context(), principal, userID)
+	} else {
+		err = u.usecase.Delete(r.Context(), principal, userID, r.Header.Get("If-Match"))
+	}
 	if err != nil {
 		common.RespondWithJSON(w, common.GetStatusCode(err), common.ResponseError{Error: err.Error()})
+		return
+	}
+
+	common.RespondWithJSON(w, http.StatusNoContent, nil)
+	return
+}
 
+func (u *UserHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		common.RespondWithJSON(w, http.StatusBadRequest, common.ResponseError{Error: common.BadRequest.Error()})
+		return
 	}
 
-	err = u.usecase.Delete(context.Background(), userID)
+	principal, _ := middleware.PrincipalFromContext(r.Context())
+	err = u.usecase.Restore(r.Context(), principal, userID)
 	if err != nil {
 		common.RespondWithJSON(w, common.GetStatusCode(err), common.ResponseError{Error: err.Error()})
 		return
@@ -88,31 +156,266 @@ func (u *UserHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	return
 }
 
-func (u *UserHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+func (u *UserHandler) Unlock(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	userID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		common.RespondWithJSON(w, http.StatusBadRequest, common.ResponseError{Error: common.BadRequest.Error()})
+		return
+	}
+
+	principal, _ := middleware.PrincipalFromContext(r.Context())
+	err = u.usecase.Unlock(r.Context(), principal, userID)
 	if err != nil {
 		common.RespondWithJSON(w, common.GetStatusCode(err), common.ResponseError{Error: err.Error()})
+		return
+	}
+
+	common.RespondWithJSON(w, http.StatusNoContent, nil)
+	return
+}
 
+func (u *UserHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		common.RespondWithJSON(w, http.StatusBadRequest, common.ResponseError{Error: common.BadRequest.Error()})
+		return
 	}
 
-	user, err := u.usecase.GetByID(context.Background(), userID)
+	principal, _ := middleware.PrincipalFromContext(r.Context())
+	user, err := u.usecase.GetByID(r.Context(), principal, userID)
 	if err != nil {
 		common.RespondWithJSON(w, common.GetStatusCode(err), common.ResponseError{Error: err.Error()})
 		return
 	}
 
+	tag := etag.FromTime(user.UpdatedAt)
+	w.Header().Set("ETag", tag)
+	if etag.MatchWeak(r.Header.Get("If-None-Match"), tag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
 	common.RespondWithJSON(w, http.StatusOK, user)
 	return
 }
 
 func (u *UserHandler) GetAllUser(w http.ResponseWriter, r *http.Request) {
-	users, err := u.usecase.GetAllUser(r.Context())
+	query := r.URL.Query()
+	limit, err := queryInt(query, "limit")
 	if err != nil {
-		common.RespondWithJSON(w, common.GetStatusCode(err), common.ResponseError{Error: err.Error()})
+		common.RespondWithJSON(w, http.StatusBadRequest, common.ResponseError{Error: common.BadRequest.Error()})
+		return
+	}
+	page, err := queryInt(query, "page")
+	if err != nil {
+		common.RespondWithJSON(w, http.StatusBadRequest, common.ResponseError{Error: common.BadRequest.Error()})
+		return
+	}
+	params := domain.UserListParams{
+		Limit:   limit,
+		Cursor:  query.Get("cursor"),
+		Page:    page,
+		Filters: userFilters(query),
+		Sort:    userSort(query.Get("sort")),
+	}
+
+	principal, _ := middleware.PrincipalFromContext(r.Context())
+	users, err := u.usecase.GetAllUser(r.Context(), principal, &params)
+	if err != nil {
+		common.RespondWithJSON(w, common.GetStatusCode(err), common.NewResponseError(err))
 		return
 	}
 
+	if links := userListLinks(users); len(links) > 0 {
+		w.Header().Set("Link", pagination.LinkHeader(r.URL, links...))
+	}
+
 	common.RespondWithJSON(w, http.StatusOK, users)
 	return
 }
+
+func (u *UserHandler) Search(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit, err := queryInt(query, "limit")
+	if err != nil {
+		common.RespondWithJSON(w, http.StatusBadRequest, common.ResponseError{Error: common.BadRequest.Error()})
+		return
+	}
+
+	principal, _ := middleware.PrincipalFromContext(r.Context())
+	users, err := u.usecase.Search(r.Context(), principal, &domain.UserSearchParams{Query: query.Get("q"), Limit: limit})
+	if err != nil {
+		common.RespondWithJSON(w, common.GetStatusCode(err), common.NewResponseError(err))
+		return
+	}
+
+	common.RespondWithJSON(w, http.StatusOK, users)
+	return
+}
+
+// queryInt reads an optional integer query parameter, zero when absent.
+func queryInt(query url.Values, key string) (int, error) {
+	if query.Get(key) == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(query.Get(key))
+}
+
+// userListParams are the query parameters of the user list that are not
+// filters.
+var userListParams = map[string]bool{"limit": true, "cursor": true, "page": true, "sort": true}
+
+// userFilters reads filters written as field=value or field[operator]=value.
+// The in operator takes a comma separated list. Unknown fields and operators
+// are passed on for the usecase to reject.
+func userFilters(query url.Values) []domain.UserFilter {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		if !userListParams[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var filters []domain.UserFilter
+	for _, key := range keys {
+		field, operator := key, domain.FilterEQ
+		if open := strings.Index(key, "["); open > 0 && strings.HasSuffix(key, "]") {
+			field, operator = key[:open], key[open+1:len(key)-1]
+		}
+
+		for _, value := range query[key] {
+			values := []string{value}
+			if operator == domain.FilterIn {
+				values = strings.Split(value, ",")
+			}
+			filters = append(filters, domain.UserFilter{Field: field, Operator: operator, Values: values})
+		}
+	}
+	return filters
+}
+
+// userSort reads a comma separated list of fields, each prefixed with - to
+// sort descending.
+func userSort(value string) []domain.UserSort {
+	if value == "" {
+		return nil
+	}
+
+	fields := strings.Split(value, ",")
+	order := make([]domain.UserSort, 0, len(fields))
+	for _, field := range fields {
+		order = append(order, domain.UserSort{
+			Field:      strings.TrimPrefix(field, "-"),
+			Descending: strings.HasPrefix(field, "-"),
+		})
+	}
+	return order
+}
+
+func userListLinks(users *domain.UserListResponse) []pagination.Link {
+	if users.Page == 0 {
+		if users.NextCursor == "" {
+			return nil
+		}
+		return []pagination.Link{{Rel: "next", Params: map[string]string{"cursor": users.NextCursor}}}
+	}
+
+	page := func(rel string, page int) pagination.Link {
+		return pagination.Link{Rel: rel, Params: map[string]string{"page": strconv.Itoa(page)}}
+	}
+
+	links := []pagination.Link{page("first", 1)}
+	if users.Page > 1 {
+		links = append(links, page("prev", users.Page-1))
+	}
+	if users.Page < users.TotalPages {
+		links = append(links, page("next", users.Page+1))
+	}
+	if users.TotalPages > 0 {
+		links = append(links, page("last", users.TotalPages))
+	}
+	return links
+}
+
+func (u *UserHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var body domain.VerifyEmailRequest
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		common.RespondWithJSON(w, http.StatusBadRequest, common.ResponseError{Error: common.BadRequest.Error()})
+		return
+	}
+
+	err = u.usecase.VerifyEmail(r.Context(), body.Token)
+	if err != nil {
+		common.RespondWithJSON(w, common.GetStatusCode(err), common.ResponseError{Error: err.Error()})
+		return
+	}
+
+	common.RespondWithJSON(w, http.StatusNoContent, nil)
+	return
+}
+
+func (u *UserHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	var body domain.ResendVerificationRequest
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		common.RespondWithJSON(w, http.StatusBadRequest, common.ResponseError{Error: common.BadRequest.Error()})
+		return
+	}
+
+	err = u.usecase.ResendVerification(r.Context(), body.Email)
+	if err != nil {
+		common.RespondWithJSON(w, common.GetStatusCode(err), common.ResponseError{Error: err.Error()})
+		return
+	}
+
+	common.RespondWithJSON(w, http.StatusAccepted, nil)
+	return
+}
+
+func (u *UserHandler) RequestEmailChange(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		common.RespondWithJSON(w, http.StatusBadRequest, common.ResponseError{Error: common.BadRequest.Error()})
+		return
+	}
+
+	var body domain.EmailChangeRequest
+	err = json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		common.RespondWithJSON(w, http.StatusBadRequest, common.ResponseError{Error: common.BadRequest.Error()})
+		return
+	}
+
+	principal, _ := middleware.PrincipalFromContext(r.Context())
+	err = u.usecase.RequestEmailChange(r.Context(), principal, userID, body.Email)
+	if err != nil {
+		common.RespondWithJSON(w, common.GetStatusCode(err), common.NewResponseError(err))
+		return
+	}
+
+	common.RespondWithJSON(w, http.StatusAccepted, nil)
+	return
+}
+
+func (u *UserHandler) ConfirmEmailChange(w http.ResponseWriter, r *http.Request) {
+	var body domain.VerifyEmailRequest
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		common.RespondWithJSON(w, http.StatusBadRequest, common.ResponseError{Error: common.BadRequest.Error()})
+		return
+	}
+
+	err = u.usecase.ConfirmEmailChange(r.Context(), body.Token)
+	if err != nil {
+		common.RespondWithJSON(w, common.GetStatusCode(err), common.ResponseError{Error: err.Error()})
+		return
+	}
+
+	common.RespondWithJSON(w, http.StatusNoContent, nil)
+	return
+}
@@ -1,22 +1,28 @@
 package handler
 
 import (
-	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/bxcodec/faker"
 	"github.com/gorilla/mux"
 	"github.com/h4yfans/case-study/common"
+	"github.com/h4yfans/case-study/common/etag"
+	"github.com/h4yfans/case-study/common/middleware"
+	"github.com/h4yfans/case-study/common/patch"
 	"github.com/h4yfans/case-study/domain"
 	mocks "github.com/h4yfans/case-study/mocks/domain"
 	"github.com/h4yfans/case-study/models"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
+var principal = &domain.Principal{UserID: 1, Role: domain.RoleUser}
+
 func TestCreate(t *testing.T) {
 	t.Run("should return 200", func(t *testing.T) {
 		// Mock body
@@ -49,6 +55,23 @@ func TestCreate(t *testing.T) {
 
 	})
 
+	t.Run("should ignore fields other than name, email and password", func(t *testing.T) {
+		body := `{"name":"Kaan","email":"kaan@test.com","password":"123123","role":"admin","email_verified_at":"2020-01-01T00:00:00Z","totp_secret":"secret"}`
+		req, err := http.NewRequest(http.MethodPut, "/users", strings.NewReader(body))
+		assert.NoError(t, err)
+
+		userBody := models.User{Name: "Kaan", Email: "kaan@test.com", Password: "123123"}
+		mockUCase := new(mocks.UserUsecase)
+		mockUCase.On("Create", req.Context(), &userBody).Return(&domain.UserResponse{ID: 1}, nil)
+
+		rec := httptest.NewRecorder()
+		handler := UserHandler{usecase: mockUCase}
+
+		handler.Create(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockUCase.AssertExpectations(t)
+	})
+
 	t.Run("should return 400", func(t *testing.T) {
 		// Mock body
 		userBody := models.User{}
@@ -70,6 +93,33 @@ func TestCreate(t *testing.T) {
 		mockUCase.AssertExpectations(t)
 	})
 
+	t.Run("should return 400 with violations", func(t *testing.T) {
+		// Mock body
+		userBody := models.User{Name: "Kaan", Email: "kaan@test.com", Password: "a"}
+		r, err := json.Marshal(&userBody)
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPut, "/users", strings.NewReader(string(r)))
+		assert.NoError(t, err)
+
+		violations := []common.Violation{{Field: "password", Rule: "min_length", Message: "Password must be at least 8 characters"}}
+
+		mockUCase := new(mocks.UserUsecase)
+		mockUCase.On("Create", req.Context(), &userBody).Return(nil, &common.ValidationError{Violations: violations})
+
+		rec := httptest.NewRecorder()
+		handler := UserHandler{usecase: mockUCase}
+
+		handler.Create(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		var body common.ResponseError
+		err = json.NewDecoder(rec.Body).Decode(&body)
+		assert.NoError(t, err)
+		assert.Equal(t, violations, body.Violations)
+		mockUCase.AssertExpectations(t)
+	})
+
 	t.Run("should return 500", func(t *testing.T) {
 		// Mock body
 		userBody := models.User{}
@@ -147,6 +197,8 @@ func TestUpdate(t *testing.T) {
 
 		req, err := http.NewRequest(http.MethodPatch, "/users/1", strings.NewReader(string(r)))
 		assert.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
 
 		// Mock response
 		userResponse := &domain.UserResponse{
@@ -155,13 +207,10 @@ func TestUpdate(t *testing.T) {
 			Email: "kaan@test.com",
 		}
 
-		userBody.ID = 1
 		mockUCase := new(mocks.UserUsecase)
-		mockUCase.On("Update", context.Background(), &userBody).Return(userResponse, nil)
+		mockUCase.On("Update", req.Context(), principal, 1, mock.AnythingOfType("patch.MergePatch"), "").Return(userResponse, nil)
 
 		rec := httptest.NewRecorder()
-		vars := map[string]string{"id": "1"}
-		req = mux.SetURLVars(req, vars)
 		handler := UserHandler{usecase: mockUCase}
 
 		handler.Update(rec, req)
@@ -179,15 +228,14 @@ func TestUpdate(t *testing.T) {
 
 		req, err := http.NewRequest(http.MethodPatch, "/users/1", strings.NewReader(string(r)))
 		assert.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
 
-		userBody.ID = 1
 		mockUCase := new(mocks.UserUsecase)
-		mockUCase.On("Update", context.Background(), &userBody).Return(nil, common.BadRequest)
+		mockUCase.On("Update", req.Context(), principal, 1, mock.AnythingOfType("patch.MergePatch"), "").Return(nil, common.BadRequest)
 
 		rec := httptest.NewRecorder()
 		handler := UserHandler{usecase: mockUCase}
-		vars := map[string]string{"id": "1"}
-		req = mux.SetURLVars(req, vars)
 
 		handler.Update(rec, req)
 		assert.NoError(t, err)
@@ -203,15 +251,14 @@ func TestUpdate(t *testing.T) {
 
 		req, err := http.NewRequest(http.MethodPatch, "/users/1", strings.NewReader(string(r)))
 		assert.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
 
-		userBody.ID = 1
 		mockUCase := new(mocks.UserUsecase)
-		mockUCase.On("Update", req.Context(), &userBody).Return(nil, common.ServerError)
+		mockUCase.On("Update", req.Context(), principal, 1, mock.AnythingOfType("patch.MergePatch"), "").Return(nil, common.ServerError)
 
 		rec := httptest.NewRecorder()
 		handler := UserHandler{usecase: mockUCase}
-		vars := map[string]string{"id": "1"}
-		req = mux.SetURLVars(req, vars)
 
 		handler.Update(rec, req)
 		assert.NoError(t, err)
@@ -227,15 +274,14 @@ func TestUpdate(t *testing.T) {
 
 		req, err := http.NewRequest(http.MethodPatch, "/users/1", strings.NewReader(string(r)))
 		assert.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
 
-		userBody.ID = 1
 		mockUCase := new(mocks.UserUsecase)
-		mockUCase.On("Update", req.Context(), &userBody).Return(nil, common.UserAlreadyExist)
+		mockUCase.On("Update", req.Context(), principal, 1, mock.AnythingOfType("patch.MergePatch"), "").Return(nil, common.UserAlreadyExist)
 
 		rec := httptest.NewRecorder()
 		handler := UserHandler{usecase: mockUCase}
-		vars := map[string]string{"id": "1"}
-		req = mux.SetURLVars(req, vars)
 
 		handler.Update(rec, req)
 		assert.NoError(t, err)
@@ -251,33 +297,104 @@ func TestUpdate(t *testing.T) {
 
 		req, err := http.NewRequest(http.MethodPatch, "/users/1", strings.NewReader(string(r)))
 		assert.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
 
-		userBody.ID = 1
 		mockUCase := new(mocks.UserUsecase)
-		mockUCase.On("Update", req.Context(), &userBody).Return(nil, common.UserNotExist)
+		mockUCase.On("Update", req.Context(), principal, 1, mock.AnythingOfType("patch.MergePatch"), "").Return(nil, common.UserNotExist)
 
 		rec := httptest.NewRecorder()
 		handler := UserHandler{usecase: mockUCase}
-		vars := map[string]string{"id": "1"}
-		req = mux.SetURLVars(req, vars)
 
 		handler.Update(rec, req)
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusNotFound, rec.Code)
 		mockUCase.AssertExpectations(t)
 	})
+
+	t.Run("should accept a JSON Patch", func(t *testing.T) {
+		body := `[{"op":"replace","path":"/name","value":"Ayse"}]`
+		req, err := http.NewRequest(http.MethodPatch, "/users/1", strings.NewReader(body))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json-patch+json")
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
+
+		changes, err := patch.NewJSONPatch([]byte(body))
+		assert.NoError(t, err)
+
+		mockUCase := new(mocks.UserUsecase)
+		mockUCase.On("Update", req.Context(), principal, 1, changes, "").Return(&domain.UserResponse{ID: 1, Name: "Ayse"}, nil)
+
+		rec := httptest.NewRecorder()
+		handler := UserHandler{usecase: mockUCase}
+
+		handler.Update(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockUCase.AssertExpectations(t)
+	})
+
+	t.Run("should return 400 on a malformed patch", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPatch, "/users/1", strings.NewReader(`[{"op":"replace"}]`))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json-patch+json")
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
+
+		mockUCase := new(mocks.UserUsecase)
+
+		rec := httptest.NewRecorder()
+		handler := UserHandler{usecase: mockUCase}
+
+		handler.Update(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockUCase.AssertExpectations(t)
+	})
+
+	t.Run("should return 400 on a malformed id", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPatch, "/users/abc", strings.NewReader(`{"name":"Ayse"}`))
+		assert.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "abc"})
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
+
+		mockUCase := new(mocks.UserUsecase)
+
+		rec := httptest.NewRecorder()
+		handler := UserHandler{usecase: mockUCase}
+
+		handler.Update(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockUCase.AssertExpectations(t)
+	})
+
+	t.Run("should return 415", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPatch, "/users/1", strings.NewReader(`name=Ayse`))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
+
+		mockUCase := new(mocks.UserUsecase)
+
+		rec := httptest.NewRecorder()
+		handler := UserHandler{usecase: mockUCase}
+
+		handler.Update(rec, req)
+		assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+		mockUCase.AssertExpectations(t)
+	})
 }
 
 func TestDelete(t *testing.T) {
 	t.Run("should return 204", func(t *testing.T) {
 		req, err := http.NewRequest(http.MethodDelete, "/users/1", strings.NewReader(""))
 		assert.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
 
 		mockUCase := new(mocks.UserUsecase)
-		mockUCase.On("Delete", context.Background(), 1).Return(nil)
+		mockUCase.On("Delete", req.Context(), principal, 1, "").Return(nil)
 
-		vars := map[string]string{"id": "1"}
-		req = mux.SetURLVars(req, vars)
 		handler := UserHandler{usecase: mockUCase}
 
 		rec := httptest.NewRecorder()
@@ -288,17 +405,51 @@ func TestDelete(t *testing.T) {
 
 	})
 
+	t.Run("should purge and return 204", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodDelete, "/users/1?purge=true", strings.NewReader(""))
+		assert.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
+
+		mockUCase := new(mocks.UserUsecase)
+		mockUCase.On("Purge", req.Context(), principal, 1).Return(nil)
+
+		handler := UserHandler{usecase: mockUCase}
+
+		rec := httptest.NewRecorder()
+		handler.Delete(rec, req)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		mockUCase.AssertExpectations(t)
+	})
+
+	t.Run("should reject If-Match on a purge", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodDelete, "/users/1?purge=true", strings.NewReader(""))
+		assert.NoError(t, err)
+		req.Header.Set("If-Match", `"abc"`)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
+
+		mockUCase := new(mocks.UserUsecase)
+
+		handler := UserHandler{usecase: mockUCase}
+
+		rec := httptest.NewRecorder()
+		handler.Delete(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockUCase.AssertExpectations(t)
+	})
+
 	t.Run("should return 400", func(t *testing.T) {
 		req, err := http.NewRequest(http.MethodDelete, "/users/1", strings.NewReader(""))
 		assert.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
 
 		mockUCase := new(mocks.UserUsecase)
-		mockUCase.On("Delete", context.Background(), 1).Return(common.BadRequest)
+		mockUCase.On("Delete", req.Context(), principal, 1, "").Return(common.BadRequest)
 
 		rec := httptest.NewRecorder()
 		handler := UserHandler{usecase: mockUCase}
-		vars := map[string]string{"id": "1"}
-		req = mux.SetURLVars(req, vars)
 
 		handler.Delete(rec, req)
 		assert.NoError(t, err)
@@ -306,17 +457,34 @@ func TestDelete(t *testing.T) {
 		mockUCase.AssertExpectations(t)
 	})
 
+	t.Run("should return 400 on a malformed id", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodDelete, "/users/abc", strings.NewReader(""))
+		assert.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "abc"})
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
+
+		mockUCase := new(mocks.UserUsecase)
+
+		rec := httptest.NewRecorder()
+		handler := UserHandler{usecase: mockUCase}
+
+		handler.Delete(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, 1, strings.Count(rec.Body.String(), "error"))
+		mockUCase.AssertExpectations(t)
+	})
+
 	t.Run("should return 500", func(t *testing.T) {
 		req, err := http.NewRequest(http.MethodDelete, "/users/1", strings.NewReader(""))
 		assert.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
 
 		mockUCase := new(mocks.UserUsecase)
-		mockUCase.On("Delete", req.Context(), 1).Return(common.ServerError)
+		mockUCase.On("Delete", req.Context(), principal, 1, "").Return(common.ServerError)
 
 		rec := httptest.NewRecorder()
 		handler := UserHandler{usecase: mockUCase}
-		vars := map[string]string{"id": "1"}
-		req = mux.SetURLVars(req, vars)
 
 		handler.Delete(rec, req)
 		assert.NoError(t, err)
@@ -327,14 +495,14 @@ func TestDelete(t *testing.T) {
 	t.Run("should return 403", func(t *testing.T) {
 		req, err := http.NewRequest(http.MethodDelete, "/users/1", strings.NewReader(""))
 		assert.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
 
 		mockUCase := new(mocks.UserUsecase)
-		mockUCase.On("Delete", req.Context(), 1).Return(common.UserAlreadyExist)
+		mockUCase.On("Delete", req.Context(), principal, 1, "").Return(common.UserAlreadyExist)
 
 		rec := httptest.NewRecorder()
 		handler := UserHandler{usecase: mockUCase}
-		vars := map[string]string{"id": "1"}
-		req = mux.SetURLVars(req, vars)
 
 		handler.Delete(rec, req)
 		assert.NoError(t, err)
@@ -345,14 +513,14 @@ func TestDelete(t *testing.T) {
 	t.Run("should return 404", func(t *testing.T) {
 		req, err := http.NewRequest(http.MethodDelete, "/users/1", strings.NewReader(""))
 		assert.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
 
 		mockUCase := new(mocks.UserUsecase)
-		mockUCase.On("Delete", req.Context(), 1).Return(common.UserNotExist)
+		mockUCase.On("Delete", req.Context(), principal, 1, "").Return(common.UserNotExist)
 
 		rec := httptest.NewRecorder()
 		handler := UserHandler{usecase: mockUCase}
-		vars := map[string]string{"id": "1"}
-		req = mux.SetURLVars(req, vars)
 
 		handler.Delete(rec, req)
 		assert.NoError(t, err)
@@ -365,14 +533,14 @@ func TestGetByID(t *testing.T) {
 	t.Run("should return 200", func(t *testing.T) {
 		req, err := http.NewRequest(http.MethodGet, "/users/1", strings.NewReader(""))
 		assert.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
 
 		userResponse := &domain.UserResponse{}
 
 		mockUCase := new(mocks.UserUsecase)
-		mockUCase.On("GetByID", context.Background(), 1).Return(userResponse, nil)
+		mockUCase.On("GetByID", req.Context(), principal, 1).Return(userResponse, nil)
 
-		vars := map[string]string{"id": "1"}
-		req = mux.SetURLVars(req, vars)
 		handler := UserHandler{usecase: mockUCase}
 
 		rec := httptest.NewRecorder()
@@ -386,14 +554,14 @@ func TestGetByID(t *testing.T) {
 	t.Run("should return 400", func(t *testing.T) {
 		req, err := http.NewRequest(http.MethodGet, "/users/1", strings.NewReader(""))
 		assert.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
 
 		mockUCase := new(mocks.UserUsecase)
-		mockUCase.On("GetByID", context.Background(), 1).Return(nil, common.BadRequest)
+		mockUCase.On("GetByID", req.Context(), principal, 1).Return(nil, common.BadRequest)
 
 		rec := httptest.NewRecorder()
 		handler := UserHandler{usecase: mockUCase}
-		vars := map[string]string{"id": "1"}
-		req = mux.SetURLVars(req, vars)
 
 		handler.GetByID(rec, req)
 		assert.NoError(t, err)
@@ -401,17 +569,34 @@ func TestGetByID(t *testing.T) {
 		mockUCase.AssertExpectations(t)
 	})
 
+	t.Run("should return 400 on a malformed id", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/users/abc", strings.NewReader(""))
+		assert.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "abc"})
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
+
+		mockUCase := new(mocks.UserUsecase)
+
+		rec := httptest.NewRecorder()
+		handler := UserHandler{usecase: mockUCase}
+
+		handler.GetByID(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, 1, strings.Count(rec.Body.String(), "error"))
+		mockUCase.AssertExpectations(t)
+	})
+
 	t.Run("should return 500", func(t *testing.T) {
 		req, err := http.NewRequest(http.MethodGet, "/users/1", strings.NewReader(""))
 		assert.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
 
 		mockUCase := new(mocks.UserUsecase)
-		mockUCase.On("GetByID", req.Context(), 1).Return(nil, common.ServerError)
+		mockUCase.On("GetByID", req.Context(), principal, 1).Return(nil, common.ServerError)
 
 		rec := httptest.NewRecorder()
 		handler := UserHandler{usecase: mockUCase}
-		vars := map[string]string{"id": "1"}
-		req = mux.SetURLVars(req, vars)
 
 		handler.GetByID(rec, req)
 		assert.NoError(t, err)
@@ -422,14 +607,14 @@ func TestGetByID(t *testing.T) {
 	t.Run("should return 403", func(t *testing.T) {
 		req, err := http.NewRequest(http.MethodGet, "/users/1", strings.NewReader(""))
 		assert.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
 
 		mockUCase := new(mocks.UserUsecase)
-		mockUCase.On("GetByID", req.Context(), 1).Return(nil, common.UserAlreadyExist)
+		mockUCase.On("GetByID", req.Context(), principal, 1).Return(nil, common.UserAlreadyExist)
 
 		rec := httptest.NewRecorder()
 		handler := UserHandler{usecase: mockUCase}
-		vars := map[string]string{"id": "1"}
-		req = mux.SetURLVars(req, vars)
 
 		handler.GetByID(rec, req)
 		assert.NoError(t, err)
@@ -440,14 +625,14 @@ func TestGetByID(t *testing.T) {
 	t.Run("should return 404", func(t *testing.T) {
 		req, err := http.NewRequest(http.MethodGet, "/users/1", strings.NewReader(""))
 		assert.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
 
 		mockUCase := new(mocks.UserUsecase)
-		mockUCase.On("GetByID", req.Context(), 1).Return(nil, common.UserNotExist)
+		mockUCase.On("GetByID", req.Context(), principal, 1).Return(nil, common.UserNotExist)
 
 		rec := httptest.NewRecorder()
 		handler := UserHandler{usecase: mockUCase}
-		vars := map[string]string{"id": "1"}
-		req = mux.SetURLVars(req, vars)
 
 		handler.GetByID(rec, req)
 		assert.NoError(t, err)
@@ -456,15 +641,76 @@ func TestGetByID(t *testing.T) {
 	})
 }
 
+func TestGetByIDConditional(t *testing.T) {
+	userResponse := &domain.UserResponse{ID: 1, UpdatedAt: time.Date(2021, 9, 1, 12, 0, 0, 0, time.UTC)}
+	tag := etag.FromTime(userResponse.UpdatedAt)
+
+	t.Run("should return an ETag", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/users/1", nil)
+		assert.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
+
+		mockUCase := new(mocks.UserUsecase)
+		mockUCase.On("GetByID", req.Context(), principal, 1).Return(userResponse, nil)
+
+		rec := httptest.NewRecorder()
+		handler := UserHandler{usecase: mockUCase}
+
+		handler.GetByID(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, tag, rec.Header().Get("ETag"))
+		mockUCase.AssertExpectations(t)
+	})
+
+	t.Run("should return 304", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/users/1", nil)
+		assert.NoError(t, err)
+		req.Header.Set("If-None-Match", tag)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
+
+		mockUCase := new(mocks.UserUsecase)
+		mockUCase.On("GetByID", req.Context(), principal, 1).Return(userResponse, nil)
+
+		rec := httptest.NewRecorder()
+		handler := UserHandler{usecase: mockUCase}
+
+		handler.GetByID(rec, req)
+		assert.Equal(t, http.StatusNotModified, rec.Code)
+		assert.Empty(t, rec.Body.String())
+		mockUCase.AssertExpectations(t)
+	})
+}
+
+func TestUpdatePrecondition(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPatch, "/users/1", strings.NewReader(`{"name":"Kaan"}`))
+	assert.NoError(t, err)
+	req.Header.Set("If-Match", `"stale"`)
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+	req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
+
+	mockUCase := new(mocks.UserUsecase)
+	mockUCase.On("Update", req.Context(), principal, 1, patch.MergePatch{"name": json.RawMessage(`"Kaan"`)}, `"stale"`).Return(nil, common.PreconditionFailed)
+
+	rec := httptest.NewRecorder()
+	handler := UserHandler{usecase: mockUCase}
+
+	handler.Update(rec, req)
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+	mockUCase.AssertExpectations(t)
+}
+
 func TestGetAllUser(t *testing.T) {
 	t.Run("should return 200", func(t *testing.T) {
 		req, err := http.NewRequest(http.MethodGet, "/users", strings.NewReader(""))
 		assert.NoError(t, err)
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
 
-		var userResponse []domain.UserResponse
+		userResponse := &domain.UserListResponse{}
 
 		mockUCase := new(mocks.UserUsecase)
-		mockUCase.On("GetAllUser", context.Background()).Return(userResponse, nil)
+		mockUCase.On("GetAllUser", req.Context(), principal, &domain.UserListParams{}).Return(userResponse, nil)
 
 		handler := UserHandler{usecase: mockUCase}
 
@@ -479,9 +725,10 @@ func TestGetAllUser(t *testing.T) {
 	t.Run("should return 400", func(t *testing.T) {
 		req, err := http.NewRequest(http.MethodGet, "/users", strings.NewReader(""))
 		assert.NoError(t, err)
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
 
 		mockUCase := new(mocks.UserUsecase)
-		mockUCase.On("GetAllUser", context.Background()).Return(nil, common.BadRequest)
+		mockUCase.On("GetAllUser", req.Context(), principal, &domain.UserListParams{}).Return(nil, common.BadRequest)
 
 		rec := httptest.NewRecorder()
 		handler := UserHandler{usecase: mockUCase}
@@ -495,9 +742,10 @@ func TestGetAllUser(t *testing.T) {
 	t.Run("should return 500", func(t *testing.T) {
 		req, err := http.NewRequest(http.MethodGet, "/users", strings.NewReader(""))
 		assert.NoError(t, err)
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
 
 		mockUCase := new(mocks.UserUsecase)
-		mockUCase.On("GetAllUser", req.Context()).Return(nil, common.ServerError)
+		mockUCase.On("GetAllUser", req.Context(), principal, &domain.UserListParams{}).Return(nil, common.ServerError)
 
 		rec := httptest.NewRecorder()
 		handler := UserHandler{usecase: mockUCase}
@@ -511,9 +759,10 @@ func TestGetAllUser(t *testing.T) {
 	t.Run("should return 403", func(t *testing.T) {
 		req, err := http.NewRequest(http.MethodGet, "/users", strings.NewReader(""))
 		assert.NoError(t, err)
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
 
 		mockUCase := new(mocks.UserUsecase)
-		mockUCase.On("GetAllUser", req.Context()).Return(nil, common.UserAlreadyExist)
+		mockUCase.On("GetAllUser", req.Context(), principal, &domain.UserListParams{}).Return(nil, common.UserAlreadyExist)
 
 		rec := httptest.NewRecorder()
 		handler := UserHandler{usecase: mockUCase}
@@ -527,16 +776,329 @@ func TestGetAllUser(t *testing.T) {
 	t.Run("should return 404", func(t *testing.T) {
 		req, err := http.NewRequest(http.MethodGet, "/users", strings.NewReader(""))
 		assert.NoError(t, err)
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
+
+		mockUCase := new(mocks.UserUsecase)
+		mockUCase.On("GetAllUser", req.Context(), principal, &domain.UserListParams{}).Return(nil, common.UserNotExist)
+
+		rec := httptest.NewRecorder()
+		handler := UserHandler{usecase: mockUCase}
+
+		handler.GetAllUser(rec, req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		mockUCase.AssertExpectations(t)
+	})
+
+	t.Run("should link the next cursor", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/users?limit=2&cursor=abc", strings.NewReader(""))
+		assert.NoError(t, err)
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
+
+		params := &domain.UserListParams{Limit: 2, Cursor: "abc"}
+		userResponse := &domain.UserListResponse{NextCursor: "def"}
+
+		mockUCase := new(mocks.UserUsecase)
+		mockUCase.On("GetAllUser", req.Context(), principal, params).Return(userResponse, nil)
+
+		rec := httptest.NewRecorder()
+		handler := UserHandler{usecase: mockUCase}
+
+		handler.GetAllUser(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `</users?cursor=def&limit=2>; rel="next"`, rec.Header().Get("Link"))
+		mockUCase.AssertExpectations(t)
+	})
+
+	t.Run("should link pages", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/users?page=2", strings.NewReader(""))
+		assert.NoError(t, err)
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
+
+		params := &domain.UserListParams{Page: 2}
+		userResponse := &domain.UserListResponse{Page: 2, TotalCount: 50, TotalPages: 3}
+
+		mockUCase := new(mocks.UserUsecase)
+		mockUCase.On("GetAllUser", req.Context(), principal, params).Return(userResponse, nil)
+
+		rec := httptest.NewRecorder()
+		handler := UserHandler{usecase: mockUCase}
+
+		handler.GetAllUser(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t,
+			`</users?page=1>; rel="first", </users?page=1>; rel="prev", </users?page=3>; rel="next", </users?page=3>; rel="last"`,
+			rec.Header().Get("Link"))
+		mockUCase.AssertExpectations(t)
+	})
+
+	t.Run("should parse filters and sort", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/users?email[suffix]=@partner.com&name[in]=Kaan,Ayse&role=admin&sort=name,-id", strings.NewReader(""))
+		assert.NoError(t, err)
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
+
+		params := &domain.UserListParams{
+			Filters: []domain.UserFilter{
+				{Field: "email", Operator: domain.FilterSuffix, Values: []string{"@partner.com"}},
+				{Field: "name", Operator: domain.FilterIn, Values: []string{"Kaan", "Ayse"}},
+				{Field: "role", Operator: domain.FilterEQ, Values: []string{"admin"}},
+			},
+			Sort: []domain.UserSort{{Field: "name"}, {Field: "id", Descending: true}},
+		}
+		validationErr := &common.ValidationError{Violations: []common.Violation{{Field: "role", Rule: "filterable"}}}
+
+		mockUCase := new(mocks.UserUsecase)
+		mockUCase.On("GetAllUser", req.Context(), principal, params).Return(nil, validationErr)
+
+		rec := httptest.NewRecorder()
+		handler := UserHandler{usecase: mockUCase}
+
+		handler.GetAllUser(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"rule":"filterable"`)
+		mockUCase.AssertExpectations(t)
+	})
+
+	t.Run("should return 400 on a malformed limit", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/users?limit=ten", strings.NewReader(""))
+		assert.NoError(t, err)
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
 
 		mockUCase := new(mocks.UserUsecase)
-		mockUCase.On("GetAllUser", req.Context()).Return(nil, common.UserNotExist)
 
 		rec := httptest.NewRecorder()
 		handler := UserHandler{usecase: mockUCase}
 
 		handler.GetAllUser(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockUCase.AssertExpectations(t)
+	})
+}
+
+func TestSearch(t *testing.T) {
+	t.Run("should return 200", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/users/search?q=kan&limit=5", strings.NewReader(""))
+		assert.NoError(t, err)
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
+
+		userResponse := &domain.UserSearchResponse{Users: []domain.UserSearchResult{{Score: 0.5}}}
+
+		mockUCase := new(mocks.UserUsecase)
+		mockUCase.On("Search", req.Context(), principal, &domain.UserSearchParams{Query: "kan", Limit: 5}).Return(userResponse, nil)
+
+		rec := httptest.NewRecorder()
+		handler := UserHandler{usecase: mockUCase}
+
+		handler.Search(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"score":0.5`)
+		mockUCase.AssertExpectations(t)
+	})
+
+	t.Run("should return 400", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/users/search", strings.NewReader(""))
+		assert.NoError(t, err)
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
+
+		validationErr := &common.ValidationError{Violations: []common.Violation{{Field: "q", Rule: "length"}}}
+
+		mockUCase := new(mocks.UserUsecase)
+		mockUCase.On("Search", req.Context(), principal, &domain.UserSearchParams{}).Return(nil, validationErr)
+
+		rec := httptest.NewRecorder()
+		handler := UserHandler{usecase: mockUCase}
+
+		handler.Search(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockUCase.AssertExpectations(t)
+	})
+}
+
+func TestVerifyEmail(t *testing.T) {
+	t.Run("should return 204", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/users/verify-email", strings.NewReader(`{"token":"token"}`))
+		assert.NoError(t, err)
+
+		mockUCase := new(mocks.UserUsecase)
+		mockUCase.On("VerifyEmail", req.Context(), "token").Return(nil)
+
+		rec := httptest.NewRecorder()
+		handler := UserHandler{usecase: mockUCase}
+
+		handler.VerifyEmail(rec, req)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		mockUCase.AssertExpectations(t)
+	})
+
+	t.Run("should return 401", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/users/verify-email", strings.NewReader(`{"token":"expired"}`))
+		assert.NoError(t, err)
+
+		mockUCase := new(mocks.UserUsecase)
+		mockUCase.On("VerifyEmail", req.Context(), "expired").Return(common.InvalidToken)
+
+		rec := httptest.NewRecorder()
+		handler := UserHandler{usecase: mockUCase}
+
+		handler.VerifyEmail(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		mockUCase.AssertExpectations(t)
+	})
+}
+
+func TestResendVerification(t *testing.T) {
+	t.Run("should return 202", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/users/verify-email/resend", strings.NewReader(`{"email":"kaan@test.com"}`))
+		assert.NoError(t, err)
+
+		mockUCase := new(mocks.UserUsecase)
+		mockUCase.On("ResendVerification", req.Context(), "kaan@test.com").Return(nil)
+
+		rec := httptest.NewRecorder()
+		handler := UserHandler{usecase: mockUCase}
+
+		handler.ResendVerification(rec, req)
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		mockUCase.AssertExpectations(t)
+	})
+}
+
+func TestRequestEmailChange(t *testing.T) {
+	t.Run("should return 202", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/users/1/email", strings.NewReader(`{"email":"kaan@partner.com"}`))
+		assert.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
+
+		mockUCase := new(mocks.UserUsecase)
+		mockUCase.On("RequestEmailChange", req.Context(), principal, 1, "kaan@partner.com").Return(nil)
+
+		rec := httptest.NewRecorder()
+		handler := UserHandler{usecase: mockUCase}
+
+		handler.RequestEmailChange(rec, req)
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		mockUCase.AssertExpectations(t)
+	})
+
+	t.Run("should return 403", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/users/1/email", strings.NewReader(`{"email":"ayse@test.com"}`))
+		assert.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
+
+		mockUCase := new(mocks.UserUsecase)
+		mockUCase.On("RequestEmailChange", req.Context(), principal, 1, "ayse@test.com").Return(common.UserAlreadyExist)
+
+		rec := httptest.NewRecorder()
+		handler := UserHandler{usecase: mockUCase}
+
+		handler.RequestEmailChange(rec, req)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		mockUCase.AssertExpectations(t)
+	})
+}
+
+func TestConfirmEmailChange(t *testing.T) {
+	t.Run("should return 204", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/users/email/confirm", strings.NewReader(`{"token":"token"}`))
+		assert.NoError(t, err)
+
+		mockUCase := new(mocks.UserUsecase)
+		mockUCase.On("ConfirmEmailChange", req.Context(), "token").Return(nil)
+
+		rec := httptest.NewRecorder()
+		handler := UserHandler{usecase: mockUCase}
+
+		handler.ConfirmEmailChange(rec, req)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		mockUCase.AssertExpectations(t)
+	})
+
+	t.Run("should return 401", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/users/email/confirm", strings.NewReader(`{"token":"token"}`))
+		assert.NoError(t, err)
+
+		mockUCase := new(mocks.UserUsecase)
+		mockUCase.On("ConfirmEmailChange", req.Context(), "token").Return(common.InvalidToken)
+
+		rec := httptest.NewRecorder()
+		handler := UserHandler{usecase: mockUCase}
+
+		handler.ConfirmEmailChange(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		mockUCase.AssertExpectations(t)
+	})
+}
+
+func TestRestore(t *testing.T) {
+	t.Run("should return 204", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/users/1/restore", nil)
 		assert.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
+
+		mockUCase := new(mocks.UserUsecase)
+		mockUCase.On("Restore", req.Context(), principal, 1).Return(nil)
+
+		rec := httptest.NewRecorder()
+		handler := UserHandler{usecase: mockUCase}
+
+		handler.Restore(rec, req)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		mockUCase.AssertExpectations(t)
+	})
+
+	t.Run("should return 404", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/users/1/restore", nil)
+		assert.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
+
+		mockUCase := new(mocks.UserUsecase)
+		mockUCase.On("Restore", req.Context(), principal, 1).Return(common.UserNotExist)
+
+		rec := httptest.NewRecorder()
+		handler := UserHandler{usecase: mockUCase}
+
+		handler.Restore(rec, req)
 		assert.Equal(t, http.StatusNotFound, rec.Code)
 		mockUCase.AssertExpectations(t)
 	})
 }
+
+func TestUnlock(t *testing.T) {
+	t.Run("should return 204", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/users/1/unlock", nil)
+		assert.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
+
+		mockUCase := new(mocks.UserUsecase)
+		mockUCase.On("Unlock", req.Context(), principal, 1).Return(nil)
+
+		rec := httptest.NewRecorder()
+		handler := UserHandler{usecase: mockUCase}
+
+		handler.Unlock(rec, req)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		mockUCase.AssertExpectations(t)
+	})
+
+	t.Run("should return 403", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/users/1/unlock", nil)
+		assert.NoError(t, err)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
+
+		mockUCase := new(mocks.UserUsecase)
+		mockUCase.On("Unlock", req.Context(), principal, 1).Return(common.Forbidden)
+
+		rec := httptest.NewRecorder()
+		handler := UserHandler{usecase: mockUCase}
+
+		handler.Unlock(rec, req)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		mockUCase.AssertExpectations(t)
+	})
+}
@@ -3,13 +3,35 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"strings"
+	"time"
 
 	"github.com/h4yfans/case-study/common"
+	"github.com/h4yfans/case-study/common/db"
 	"github.com/h4yfans/case-study/domain"
 	"github.com/h4yfans/case-study/models"
+	"github.com/volatiletech/null/v8"
 	"github.com/volatiletech/sqlboiler/v4/boil"
+	"github.com/volatiletech/sqlboiler/v4/queries"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
 )
 
+// searchQuery ranks users by full-text match on their name and email plus
+// trigram word similarity, which still finds partial and misspelled names.
+// Both parts are served by the indexes of the add_user_search_indexes
+// migration.
+const searchQuery = `
+SELECT users.*,
+       ts_rank(to_tsvector('simple', name || ' ' || email), plainto_tsquery('simple', $1)) +
+       greatest(word_similarity($1, name), word_similarity($1, email)) AS score
+FROM users
+WHERE deleted_at IS NULL
+  AND (to_tsvector('simple', name || ' ' || email) @@ plainto_tsquery('simple', $1)
+    OR $1 <% name
+    OR $1 <% email)
+ORDER BY score DESC, id
+LIMIT $2`
+
 type UserRepository struct {
 	db *sql.DB
 }
@@ -20,47 +42,246 @@ func NewUserRepository(db *sql.DB) domain.UserRepository {
 	}
 }
 
+// Create inserts the user. The email's unique constraint, not a lookup
+// beforehand, decides between concurrent signups with the same address; the
+// loser gets common.UserAlreadyExist.
 func (u *UserRepository) Create(ctx context.Context, user *models.User) (*models.User, error) {
-	exists, err := u.getByEmail(ctx, user.Email)
+	err := user.Insert(ctx, u.db, boil.Infer())
 	if err != nil {
-		return nil, err
+		return nil, userError(err)
+	}
+
+	return user, nil
+}
+
+// Update saves the given columns of the user, only if it was not modified
+// since the caller read it, which is when its updated_at still equals
+// user.UpdatedAt. Otherwise it reports common.Conflict.
+func (u *UserRepository) Update(ctx context.Context, user *models.User, columns []string) (*models.User, error) {
+	values := models.M{models.UserColumns.UpdatedAt: time.Now()}
+	for _, column := range columns {
+		switch column {
+		case models.UserColumns.Name:
+			values[column] = user.Name
+		case models.UserColumns.Password:
+			values[column] = user.Password
+		default:
+			return nil, common.ServerError
+		}
+	}
+
+	effected, err := models.Users(
+		models.UserWhere.ID.EQ(user.ID),
+		models.UserWhere.UpdatedAt.EQ(user.UpdatedAt),
+	).UpdateAll(ctx, u.db, values)
+	if err != nil {
+		return nil, userError(err)
+	}
+
+	if effected == 0 {
+		return nil, common.Conflict
+	}
+
+	userData, err := u.GetByID(ctx, user.ID)
+	if err != nil {
+		return nil, userError(err)
+	}
+
+	return userData, nil
+}
+
+func (u *UserRepository) UpdatePassword(ctx context.Context, id int, password string) error {
+	user := models.User{ID: id, Password: password}
+	effected, err := user.Update(ctx, u.db, boil.Whitelist(models.UserColumns.Password, models.UserColumns.UpdatedAt))
+	if err != nil {
+		return userError(err)
+	}
+
+	if effected == 0 {
+		return common.UserNotExist
+	}
+
+	return nil
+}
+
+func (u *UserRepository) MarkEmailVerified(ctx context.Context, id int) error {
+	user := models.User{ID: id, EmailVerifiedAt: null.TimeFrom(time.Now())}
+	effected, err := user.Update(ctx, u.db, boil.Whitelist(models.UserColumns.EmailVerifiedAt, models.UserColumns.UpdatedAt))
+	if err != nil {
+		return userError(err)
+	}
+
+	if effected == 0 {
+		return common.UserNotExist
+	}
+
+	return nil
+}
+
+// SetPendingEmail stores the address the user asked to change to, reporting
+// common.UserAlreadyExist if another account has it.
+func (u *UserRepository) SetPendingEmail(ctx context.Context, id int, email string) error {
+	exists, err := u.getByEmail(ctx, email)
+	if err != nil {
+		return err
 	}
 	if exists {
-		return nil, common.UserAlreadyExist
+		return common.UserAlreadyExist
 	}
 
-	err = user.Insert(ctx, u.db, boil.Infer())
+	user := models.User{ID: id, PendingEmail: null.StringFrom(email)}
+	effected, err := user.Update(ctx, u.db, boil.Whitelist(models.UserColumns.PendingEmail))
 	if err != nil {
-		return nil, common.ServerError
+		return userError(err)
 	}
 
-	return user, nil
+	if effected == 0 {
+		return common.UserNotExist
+	}
+
+	return nil
 }
 
-func (u *UserRepository) Update(ctx context.Context, user *models.User) (*models.User, error) {
-	whitelist := []string{models.UserColumns.Name, models.UserColumns.Password}
-	effected, err := user.Update(ctx, u.db, boil.Whitelist(whitelist...))
+// ChangeEmail swaps in the pending address, which must still be email, and
+// marks it verified. Another account may have claimed the address since it
+// was requested, in which case the pending address is dropped and
+// common.UserAlreadyExist is reported.
+func (u *UserRepository) ChangeEmail(ctx context.Context, id int, email string) error {
+	pending := models.Users(
+		models.UserWhere.ID.EQ(id),
+		models.UserWhere.PendingEmail.EQ(null.StringFrom(email)),
+	)
+
+	effected, err := pending.UpdateAll(ctx, u.db, models.M{
+		models.UserColumns.Email:           email,
+		models.UserColumns.PendingEmail:    nil,
+		models.UserColumns.EmailVerifiedAt: time.Now(),
+		models.UserColumns.UpdatedAt:       time.Now(),
+	})
 	if err != nil {
-		return nil, err
+		err = userError(err)
+		if err != common.UserAlreadyExist {
+			return err
+		}
+
+		_, clearErr := pending.UpdateAll(ctx, u.db, models.M{models.UserColumns.PendingEmail: nil})
+		if clearErr != nil {
+			return userError(clearErr)
+		}
+		return err
 	}
 
 	if effected == 0 {
-		return nil, common.UserNotExist
+		return common.InvalidToken
 	}
 
-	userData, err := u.GetByID(ctx, user.ID)
+	return nil
+}
+
+// SetTOTPSecret stores a new sealed secret. The second factor stays disabled
+// until EnableTOTP confirms the user can produce codes for it.
+func (u *UserRepository) SetTOTPSecret(ctx context.Context, id int, secret string) error {
+	user := models.User{ID: id, TotpSecret: null.StringFrom(secret)}
+	effected, err := user.Update(ctx, u.db, boil.Whitelist(
+		models.UserColumns.TotpSecret,
+		models.UserColumns.TotpEnabledAt,
+		models.UserColumns.TotpLastCounter,
+	))
 	if err != nil {
-		return nil, common.ServerError
+		return userError(err)
 	}
 
-	return userData, nil
+	if effected == 0 {
+		return common.UserNotExist
+	}
+
+	return nil
 }
 
-func (u *UserRepository) Delete(ctx context.Context, id int) error {
-	user := models.User{ID: id}
-	effected, err := user.Delete(ctx, u.db)
+func (u *UserRepository) EnableTOTP(ctx context.Context, id int) error {
+	effected, err := models.Users(
+		models.UserWhere.ID.EQ(id),
+		models.UserWhere.TotpSecret.IsNotNull(),
+	).UpdateAll(ctx, u.db, models.M{
+		models.UserColumns.TotpEnabledAt: time.Now(),
+		models.UserColumns.UpdatedAt:     time.Now(),
+	})
 	if err != nil {
-		return common.ServerError
+		return userError(err)
+	}
+
+	if effected == 0 {
+		return common.UserNotExist
+	}
+
+	return nil
+}
+
+// UseTOTPCounter records the time step of an accepted code, reporting
+// common.InvalidTwoFactorCode if that step or a later one was already used.
+func (u *UserRepository) UseTOTPCounter(ctx context.Context, id int, counter int64) error {
+	effected, err := models.Users(
+		models.UserWhere.ID.EQ(id),
+		qm.Expr(
+			models.UserWhere.TotpLastCounter.IsNull(),
+			qm.Or2(models.UserWhere.TotpLastCounter.LT(null.Int64From(counter))),
+		),
+	).UpdateAll(ctx, u.db, models.M{models.UserColumns.TotpLastCounter: counter})
+	if err != nil {
+		return userError(err)
+	}
+
+	if effected == 0 {
+		return common.InvalidTwoFactorCode
+	}
+
+	return nil
+}
+
+// Delete soft deletes the user, only if it was not modified since the caller
+// read it, and otherwise reports common.Conflict. Deleted users are left out
+// of every read until they are restored.
+func (u *UserRepository) Delete(ctx context.Context, user *models.User) error {
+	effected, err := models.Users(
+		models.UserWhere.ID.EQ(user.ID),
+		models.UserWhere.UpdatedAt.EQ(user.UpdatedAt),
+	).DeleteAll(ctx, u.db, false)
+	if err != nil {
+		return userError(err)
+	}
+
+	if effected == 0 {
+		return common.Conflict
+	}
+
+	return nil
+}
+
+func (u *UserRepository) Restore(ctx context.Context, id int) error {
+	effected, err := models.Users(
+		qm.WithDeleted(),
+		models.UserWhere.ID.EQ(id),
+		models.UserWhere.DeletedAt.IsNotNull(),
+	).UpdateAll(ctx, u.db, models.M{
+		models.UserColumns.DeletedAt: nil,
+		models.UserColumns.UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		return userError(err)
+	}
+
+	if effected == 0 {
+		return common.UserNotExist
+	}
+
+	return nil
+}
+
+// Purge removes the user row for good, whether or not it was soft deleted.
+func (u *UserRepository) Purge(ctx context.Context, id int) error {
+	effected, err := models.Users(qm.WithDeleted(), models.UserWhere.ID.EQ(id)).DeleteAll(ctx, u.db, true)
+	if err != nil {
+		return userError(err)
 	}
 
 	if effected == 0 {
@@ -72,26 +293,217 @@ func (u *UserRepository) Delete(ctx context.Context, id int) error {
 
 func (u *UserRepository) GetByID(ctx context.Context, id int) (*models.User, error) {
 	user, err := models.FindUser(ctx, u.db, id)
+	if err == sql.ErrNoRows {
+		return nil, common.UserNotExist
+	}
 	if err != nil {
+		return nil, userError(err)
+	}
+
+	return user, nil
+}
+
+func (u *UserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	user, err := models.Users(models.UserWhere.Email.EQ(email)).One(ctx, u.db)
+	if err == sql.ErrNoRows {
 		return nil, common.UserNotExist
 	}
+	if err != nil {
+		return nil, userError(err)
+	}
 
 	return user, nil
 }
 
-func (u *UserRepository) GetAllUser(ctx context.Context) (models.UserSlice, error) {
-	users, err := models.Users().All(ctx, u.db)
+func (u *UserRepository) GetAllUser(ctx context.Context, query *domain.UserListQuery) (models.UserSlice, error) {
+	mods, err := userFilterMods(query.Filters)
 	if err != nil {
-		return nil, common.ServerError
+		return nil, err
+	}
+
+	for _, sort := range query.Sort {
+		column, ok := userColumns[sort.Field]
+		if !ok {
+			return nil, common.BadRequest
+		}
+		if sort.Descending {
+			column += " DESC"
+		}
+		mods = append(mods, qm.OrderBy(column))
+	}
+	if query.After != nil {
+		after, err := userAfterMod(query.Sort, query.After)
+		if err != nil {
+			return nil, err
+		}
+		mods = append(mods, after)
+	}
+	if query.Offset > 0 {
+		mods = append(mods, qm.Offset(query.Offset))
+	}
+	mods = append(mods, qm.Limit(query.Limit))
+
+	users, err := models.Users(mods...).All(ctx, u.db)
+	if err != nil {
+		return nil, userError(err)
 	}
 
 	return users, nil
 }
 
+func (u *UserRepository) CountUsers(ctx context.Context, filters []domain.UserFilter) (int64, error) {
+	mods, err := userFilterMods(filters)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := models.Users(mods...).Count(ctx, u.db)
+	if err != nil {
+		return 0, userError(err)
+	}
+
+	return count, nil
+}
+
 func (u *UserRepository) getByEmail(ctx context.Context, email string) (bool, error) {
-	exists, err := models.Users(models.UserWhere.Email.EQ(email)).Exists(ctx, u.db)
+	// Deleted users keep their email so they can be restored.
+	exists, err := models.Users(qm.WithDeleted(), models.UserWhere.Email.EQ(email)).Exists(ctx, u.db)
 	if err != nil {
-		return exists, common.ServerError
+		return exists, userError(err)
 	}
 	return exists, err
 }
+
+// userEmailKey is the unique constraint on users.email.
+const userEmailKey = "users_email_key"
+
+// userError translates a database error into the domain error callers
+// report. Anything it does not recognise is a server error.
+func userError(err error) error {
+	switch db.Classify(err) {
+	case db.ClassUniqueViolation:
+		if db.Constraint(err) == userEmailKey {
+			return common.UserAlreadyExist
+		}
+	case db.ClassForeignKeyViolation, db.ClassNotNullViolation, db.ClassCheckViolation:
+		return common.BadRequest
+	case db.ClassConflict:
+		return common.Conflict
+	case db.ClassTimeout:
+		return common.Timeout
+	}
+	return common.ServerError
+}
+
+type userSearchRow struct {
+	models.User `boil:",bind"`
+	Score       float64 `boil:"score"`
+}
+
+func (u *UserRepository) Search(ctx context.Context, query string, limit int) ([]domain.UserSearchHit, error) {
+	var rows []*userSearchRow
+	err := queries.Raw(searchQuery, query, limit).Bind(ctx, u.db, &rows)
+	if err != nil {
+		return nil, userError(err)
+	}
+
+	hits := make([]domain.UserSearchHit, 0, len(rows))
+	for _, row := range rows {
+		user := row.User
+		hits = append(hits, domain.UserSearchHit{User: &user, Score: row.Score})
+	}
+
+	return hits, nil
+}
+
+// userColumns maps the list fields clients may name to their columns. Only
+// these ever reach the query text; values are always bound as arguments.
+var userColumns = map[string]string{
+	"id":    models.UserTableColumns.ID,
+	"name":  models.UserTableColumns.Name,
+	"email": models.UserTableColumns.Email,
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func userFilterMods(filters []domain.UserFilter) ([]qm.QueryMod, error) {
+	mods := make([]qm.QueryMod, 0, len(filters))
+	for _, filter := range filters {
+		var where whereHelper
+		switch filter.Field {
+		case "name":
+			where = models.UserWhere.Name
+		case "email":
+			where = models.UserWhere.Email
+		default:
+			return nil, common.BadRequest
+		}
+
+		if len(filter.Values) == 0 {
+			return nil, common.BadRequest
+		}
+		value := filter.Values[0]
+		like := userColumns[filter.Field] + " ILIKE ?"
+
+		switch filter.Operator {
+		case domain.FilterEQ:
+			mods = append(mods, where.EQ(value))
+		case domain.FilterIn:
+			mods = append(mods, where.IN(filter.Values))
+		case domain.FilterPrefix:
+			mods = append(mods, qm.Where(like, likeEscaper.Replace(value)+"%"))
+		case domain.FilterSuffix:
+			mods = append(mods, qm.Where(like, "%"+likeEscaper.Replace(value)))
+		case domain.FilterContains:
+			mods = append(mods, qm.Where(like, "%"+likeEscaper.Replace(value)+"%"))
+		default:
+			return nil, common.BadRequest
+		}
+	}
+	return mods, nil
+}
+
+// whereHelper is the part of the generated string where helpers the filters
+// use.
+type whereHelper interface {
+	EQ(x string) qm.QueryMod
+	IN(slice []string) qm.QueryMod
+}
+
+// userAfterMod selects the rows that follow the cursor in the given order:
+// (a > x) OR (a = x AND b > y) OR ..., flipping the comparison on
+// descending fields.
+func userAfterMod(sort []domain.UserSort, cursor *domain.UserCursor) (qm.QueryMod, error) {
+	if len(cursor.Values) != len(sort) {
+		return nil, common.BadRequest
+	}
+
+	value := func(i int) interface{} {
+		if sort[i].Field == "id" {
+			return cursor.ID
+		}
+		return cursor.Values[i]
+	}
+
+	branches := make([]qm.QueryMod, 0, len(sort))
+	for i := range sort {
+		branch := make([]qm.QueryMod, 0, i+1)
+		for j := 0; j < i; j++ {
+			branch = append(branch, qm.Where(userColumns[sort[j].Field]+" = ?", value(j)))
+		}
+
+		op := " > ?"
+		if sort[i].Descending {
+			op = " < ?"
+		}
+		branch = append(branch, qm.Where(userColumns[sort[i].Field]+op, value(i)))
+
+		if i == 0 {
+			branches = append(branches, qm.Expr(branch...))
+		} else {
+			branches = append(branches, qm.Or2(qm.Expr(branch...)))
+		}
+	}
+
+	return qm.Expr(branches...), nil
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/h4yfans/case-study/common/tracing"
+	"github.com/h4yfans/case-study/domain"
+	"github.com/h4yfans/case-study/models"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingUserRepository runs every query of the repository it wraps in a
+// span of its own.
+type TracingUserRepository struct {
+	next domain.UserRepository
+}
+
+func NewTracingUserRepository(next domain.UserRepository) *TracingUserRepository {
+	return &TracingUserRepository{
+		next: next,
+	}
+}
+
+func (t *TracingUserRepository) start(ctx context.Context, operation string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, "UserRepository."+operation,
+		semconv.DBSystemPostgreSQL,
+		semconv.DBSQLTableKey.String(models.TableNames.Users),
+		semconv.DBOperationKey.String(operation),
+	)
+}
+
+func (t *TracingUserRepository) end(span trace.Span, err *error) {
+	tracing.End(span, *err)
+}
+
+func (t *TracingUserRepository) Create(ctx context.Context, user *models.User) (result *models.User, err error) {
+	ctx, span := t.start(ctx, "Create")
+	defer t.end(span, &err)
+	return t.next.Create(ctx, user)
+}
+
+func (t *TracingUserRepository) Update(ctx context.Context, user *models.User, columns []string) (result *models.User, err error) {
+	ctx, span := t.start(ctx, "Update")
+	defer t.end(span, &err)
+	return t.next.Update(ctx, user, columns)
+}
+
+func (t *TracingUserRepository) UpdatePassword(ctx context.Context, id int, password string) (err error) {
+	ctx, span := t.start(ctx, "UpdatePassword")
+	defer t.end(span, &err)
+	return t.next.UpdatePassword(ctx, id, password)
+}
+
+func (t *TracingUserRepository) MarkEmailVerified(ctx context.Context, id int) (err error) {
+	ctx, span := t.start(ctx, "MarkEmailVerified")
+	defer t.end(span, &err)
+	return t.next.MarkEmailVerified(ctx, id)
+}
+
+func (t *TracingUserRepository) SetPendingEmail(ctx context.Context, id int, email string) (err error) {
+	ctx, span := t.start(ctx, "SetPendingEmail")
+	defer t.end(span, &err)
+	return t.next.SetPendingEmail(ctx, id, email)
+}
+
+func (t *TracingUserRepository) ChangeEmail(ctx context.Context, id int, email string) (err error) {
+	ctx, span := t.start(ctx, "ChangeEmail")
+	defer t.end(span, &err)
+	return t.next.ChangeEmail(ctx, id, email)
+}
+
+func (t *TracingUserRepository) SetTOTPSecret(ctx context.Context, id int, secret string) (err error) {
+	ctx, span := t.start(ctx, "SetTOTPSecret")
+	defer t.end(span, &err)
+	return t.next.SetTOTPSecret(ctx, id, secret)
+}
+
+func (t *TracingUserRepository) EnableTOTP(ctx context.Context, id int) (err error) {
+	ctx, span := t.start(ctx, "EnableTOTP")
+	defer t.end(span, &err)
+	return t.next.EnableTOTP(ctx, id)
+}
+
+func (t *TracingUserRepository) UseTOTPCounter(ctx context.Context, id int, counter int64) (err error) {
+	ctx, span := t.start(ctx, "UseTOTPCounter")
+	defer t.end(span, &err)
+	return t.next.UseTOTPCounter(ctx, id, counter)
+}
+
+func (t *TracingUserRepository) Delete(ctx context.Context, user *models.User) (err error) {
+	ctx, span := t.start(ctx, "Delete")
+	defer t.end(span, &err)
+	return t.next.Delete(ctx, user)
+}
+
+func (t *TracingUserRepository) Restore(ctx context.Context, id int) (err error) {
+	ctx, span := t.start(ctx, "Restore")
+	defer t.end(span, &err)
+	return t.next.Restore(ctx, id)
+}
+
+func (t *TracingUserRepository) Purge(ctx context.Context, id int) (err error) {
+	ctx, span := t.start(ctx, "Purge")
+	defer t.end(span, &err)
+	return t.next.Purge(ctx, id)
+}
+
+func (t *TracingUserRepository) GetByID(ctx context.Context, id int) (result *models.User, err error) {
+	ctx, span := t.start(ctx, "GetByID")
+	defer t.end(span, &err)
+	return t.next.GetByID(ctx, id)
+}
+
+func (t *TracingUserRepository) GetByEmail(ctx context.Context, email string) (result *models.User, err error) {
+	ctx, span := t.start(ctx, "GetByEmail")
+	defer t.end(span, &err)
+	return t.next.GetByEmail(ctx, email)
+}
+
+func (t *TracingUserRepository) GetAllUser(ctx context.Context, query *domain.UserListQuery) (result models.UserSlice, err error) {
+	ctx, span := t.start(ctx, "GetAllUser")
+	defer t.end(span, &err)
+	return t.next.GetAllUser(ctx, query)
+}
+
+func (t *TracingUserRepository) CountUsers(ctx context.Context, filters []domain.UserFilter) (count int64, err error) {
+	ctx, span := t.start(ctx, "CountUsers")
+	defer t.end(span, &err)
+	return t.next.CountUsers(ctx, filters)
+}
+
+func (t *TracingUserRepository) Search(ctx context.Context, query string, limit int) (result []domain.UserSearchHit, err error) {
+	ctx, span := t.start(ctx, "Search")
+	defer t.end(span, &err)
+	return t.next.Search(ctx, query, limit)
+}
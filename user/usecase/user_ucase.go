@@ -2,83 +2,279 @@ package usecase
 
 import (
 	"context"
-	"net/mail"
+	"encoding/json"
+	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/h4yfans/case-study/common"
+	"github.com/h4yfans/case-study/common/emailaddr"
+	"github.com/h4yfans/case-study/common/etag"
+	"github.com/h4yfans/case-study/common/lockout"
+	"github.com/h4yfans/case-study/common/logging"
+	"github.com/h4yfans/case-study/common/pagination"
+	"github.com/h4yfans/case-study/common/password"
+	"github.com/h4yfans/case-study/common/patch"
+	"github.com/h4yfans/case-study/common/token"
 	"github.com/h4yfans/case-study/domain"
 	"github.com/h4yfans/case-study/models"
-	"golang.org/x/crypto/bcrypt"
+	"go.uber.org/zap"
 )
 
+// maxSearchLength bounds search queries; trigram matching on longer input is
+// costly and never useful.
+const maxSearchLength = 100
+
 type UserUsecase struct {
-	repo domain.UserRepository
+	repo          domain.UserRepository
+	userTokenRepo domain.UserTokenRepository
+	attemptRepo   domain.LoginAttemptRepository
+	notifier      domain.Notifier
+	tokens        *token.Manager
+	hasher        domain.PasswordHasher
+	passwords     *password.Policy
+	screener      domain.PasswordScreener
+	emails        *emailaddr.Normalizer
 }
 
-func NewUserUsecase(repo domain.UserRepository) *UserUsecase {
+func NewUserUsecase(
+	repo domain.UserRepository,
+	userTokenRepo domain.UserTokenRepository,
+	attemptRepo domain.LoginAttemptRepository,
+	notifier domain.Notifier,
+	tokens *token.Manager,
+	hasher domain.PasswordHasher,
+	passwords *password.Policy,
+	screener domain.PasswordScreener,
+	emails *emailaddr.Normalizer,
+) *UserUsecase {
 	return &UserUsecase{
-		repo: repo,
+		repo:          repo,
+		userTokenRepo: userTokenRepo,
+		attemptRepo:   attemptRepo,
+		notifier:      notifier,
+		tokens:        tokens,
+		hasher:        hasher,
+		passwords:     passwords,
+		screener:      screener,
+		emails:        emails,
 	}
 }
 
+// Create signs up a user. Only the name, email and password are taken from
+// user; everything else about a new account is decided here or by the
+// database, so a client cannot, say, mark its email verified.
 func (u *UserUsecase) Create(ctx context.Context, user *models.User) (*domain.UserResponse, error) {
-	ok := u.validate(user, true)
-	if !ok {
+	email, err := u.emails.Normalize(user.Email)
+	if err != nil || strings.TrimSpace(user.Name) == "" {
 		return nil, common.BadRequest
 	}
 
-	password, err := u.HashPassword(user.Password)
+	err = u.passwords.Validate(ctx, u.screener, user.Password, user.Name, email)
+	if err != nil {
+		return nil, err
+	}
+
+	password, err := u.hasher.HashPassword(user.Password)
 	if err != nil {
 		return nil, common.BadRequest
 	}
 
-	//hashed password
-	user.Password = password
+	account := &models.User{
+		Name:     user.Name,
+		Email:    email,
+		Password: password,
+		//signup never grants elevated roles
+		Role: domain.RoleUser,
+	}
 
-	userData, err := u.repo.Create(ctx, user)
+	userData, err := u.repo.Create(ctx, account)
 	if err != nil {
 		return nil, err
 	}
 
+	// The account exists at this point, a lost email can be resent.
+	err = u.sendVerification(ctx, userData)
+	if err != nil {
+		logging.FromContext(ctx).Error("Verification email could not be sent", zap.Error(err), zap.Int("user_id", userData.ID))
+	}
+
 	serializer := domain.UserSerializer(userData)
 
 	return serializer, nil
 }
 
-func (u *UserUsecase) Update(ctx context.Context, user *models.User) (*domain.UserResponse, error) {
-	ok := u.validate(user, false)
-	if !ok {
-		return nil, common.BadRequest
+// Update applies a patch to the user. The patch sees the user as a flat
+// document of its public fields; name and password may change, the others
+// may only be restated. Only the fields that actually change are validated
+// and saved.
+func (u *UserUsecase) Update(ctx context.Context, principal *domain.Principal, id int, changes patch.Patch, ifMatch string) (*domain.UserResponse, error) {
+	err := u.authorize(principal, id, domain.PermissionUsersUpdate, domain.PermissionUsersUpdateAny)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if ifMatch != "" && !etag.Match(ifMatch, etag.FromTime(current.UpdatedAt)) {
+		return nil, common.PreconditionFailed
+	}
+
+	original, err := userDocument(current)
+	if err != nil {
+		return nil, common.ServerError
+	}
+	doc := make(patch.Document, len(original))
+	for name, value := range original {
+		doc[name] = value
 	}
 
-	password, err := u.HashPassword(user.Password)
+	err = changes.Apply(doc)
+	if err == patch.ErrTestFailed {
+		return nil, common.PreconditionFailed
+	}
 	if err != nil {
 		return nil, common.BadRequest
 	}
 
-	//hashed password
-	user.Password = password
+	// The repository only saves over the version read here.
+	user := &models.User{ID: id, Name: current.Name, UpdatedAt: current.UpdatedAt}
+	columns, violations := readUserDocument(doc, original, user)
+	if len(violations) > 0 {
+		return nil, &common.ValidationError{Violations: violations}
+	}
+	if len(columns) == 0 {
+		return domain.UserSerializer(current), nil
+	}
+
+	if contains(columns, models.UserColumns.Password) {
+		err = u.passwords.Validate(ctx, u.screener, user.Password, user.Name, current.Email)
+		if err != nil {
+			return nil, err
+		}
+
+		user.Password, err = u.hasher.HashPassword(user.Password)
+		if err != nil {
+			return nil, common.BadRequest
+		}
+	}
+
+	userData, err := u.repo.Update(ctx, user, columns)
+	if err != nil {
+		return nil, concurrentChange(err, ifMatch)
+	}
+
+	return domain.UserSerializer(userData), nil
+}
+
+// concurrentChange reports a change made since the user was read as a failed
+// precondition if the client sent one, and as a conflict otherwise.
+func concurrentChange(err error, ifMatch string) error {
+	if err == common.Conflict && ifMatch != "" {
+		return common.PreconditionFailed
+	}
+	return err
+}
 
-	userData, err := u.repo.Update(ctx, user)
+// userDocument is the document patches to a user apply to.
+func userDocument(user *models.User) (patch.Document, error) {
+	data, err := json.Marshal(domain.UserSerializer(user))
 	if err != nil {
 		return nil, err
 	}
 
-	serializer := domain.UserSerializer(userData)
+	var doc patch.Document
+	err = json.Unmarshal(data, &doc)
+	return doc, err
+}
 
-	return serializer, nil
+// readUserDocument copies the changed name and password of a patched
+// document into user and returns their columns, or the violations that
+// prevent saving it.
+func readUserDocument(doc patch.Document, original patch.Document, user *models.User) ([]string, []common.Violation) {
+	var (
+		columns    []string
+		violations []common.Violation
+	)
+
+	names := make([]string, 0, len(doc))
+	for name := range doc {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		switch name {
+		case "name":
+			var value string
+			if json.Unmarshal(doc[name], &value) != nil || strings.TrimSpace(value) == "" {
+				violations = append(violations, common.Violation{Field: name, Rule: "required", Message: "name must be a non-empty string"})
+				continue
+			}
+			if value != user.Name {
+				user.Name = value
+				columns = append(columns, models.UserColumns.Name)
+			}
+		case "password":
+			if json.Unmarshal(doc[name], &user.Password) != nil {
+				violations = append(violations, common.Violation{Field: name, Rule: "required", Message: "password must be a string"})
+				continue
+			}
+			columns = append(columns, models.UserColumns.Password)
+		default:
+			if value, ok := original[name]; !ok || !patch.Equal(value, doc[name]) {
+				violations = append(violations, common.Violation{Field: name, Rule: "immutable", Message: fmt.Sprintf("%s cannot be changed", name)})
+			}
+		}
+	}
+
+	originals := make([]string, 0, len(original))
+	for name := range original {
+		originals = append(originals, name)
+	}
+	sort.Strings(originals)
+
+	for _, name := range originals {
+		if _, ok := doc[name]; !ok {
+			violations = append(violations, common.Violation{Field: name, Rule: "required", Message: fmt.Sprintf("%s cannot be removed", name)})
+		}
+	}
+
+	return columns, violations
 }
 
-func (u *UserUsecase) Delete(ctx context.Context, id int) error {
-	err := u.repo.Delete(ctx, id)
+func (u *UserUsecase) Delete(ctx context.Context, principal *domain.Principal, id int, ifMatch string) error {
+	err := u.authorize(principal, id, domain.PermissionUsersDelete, domain.PermissionUsersDeleteAny)
+	if err != nil {
+		return err
+	}
+
+	current, err := u.repo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
+	if ifMatch != "" && !etag.Match(ifMatch, etag.FromTime(current.UpdatedAt)) {
+		return common.PreconditionFailed
+	}
+
+	// The repository only deletes the version read here.
+	err = u.repo.Delete(ctx, current)
+	if err != nil {
+		return concurrentChange(err, ifMatch)
+	}
 
 	return nil
 }
 
-func (u *UserUsecase) GetByID(ctx context.Context, id int) (*domain.UserResponse, error) {
+func (u *UserUsecase) GetByID(ctx context.Context, principal *domain.Principal, id int) (*domain.UserResponse, error) {
+	err := u.authorize(principal, id, domain.PermissionUsersRead, domain.PermissionUsersReadAny)
+	if err != nil {
+		return nil, err
+	}
+
 	user, err := u.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -89,33 +285,438 @@ func (u *UserUsecase) GetByID(ctx context.Context, id int) (*domain.UserResponse
 	return serializer, nil
 }
 
-func (u *UserUsecase) GetAllUser(ctx context.Context) ([]domain.UserResponse, error) {
-	users, err := u.repo.GetAllUser(ctx)
+// GetAllUser returns one page of users. Without a page number it pages by
+// cursor; with one it pages by offset and also reports the totals admin UIs
+// need, at the cost of a count query.
+func (u *UserUsecase) GetAllUser(ctx context.Context, principal *domain.Principal, params *domain.UserListParams) (*domain.UserListResponse, error) {
+	if principal == nil {
+		return nil, common.Unauthorized
+	}
+	if !principal.Can(domain.PermissionUsersList) {
+		return nil, common.Forbidden
+	}
+	if params.Page < 0 || (params.Page > 0 && params.Cursor != "") {
+		return nil, common.BadRequest
+	}
+	if violations := checkListParams(params); len(violations) > 0 {
+		return nil, &common.ValidationError{Violations: violations}
+	}
+
+	limit := pagination.Limit(params.Limit)
+	query := &domain.UserListQuery{
+		Limit:   limit + 1,
+		Filters: params.Filters,
+		Sort:    userSort(params.Sort),
+	}
+	if params.Cursor != "" {
+		var cursor domain.UserCursor
+		if err := pagination.DecodeCursor(params.Cursor, &cursor); err != nil || cursor.ID <= 0 || len(cursor.Values) != len(query.Sort) {
+			return nil, common.BadRequest
+		}
+		query.After = &cursor
+	}
+	if params.Page > 0 {
+		query.Offset = (params.Page - 1) * limit
+	}
+
+	users, err := u.repo.GetAllUser(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 
-	serializers := make([]domain.UserResponse, 0)
+	response := &domain.UserListResponse{Users: make([]domain.UserResponse, 0, limit)}
+	hasMore := len(users) > limit
+	if hasMore {
+		users = users[:limit]
+	}
 	for _, user := range users {
-		serializers = append(serializers, *domain.UserSerializer(user))
+		response.Users = append(response.Users, *domain.UserSerializer(user))
+	}
+
+	if params.Page > 0 {
+		total, err := u.repo.CountUsers(ctx, params.Filters)
+		if err != nil {
+			return nil, err
+		}
+		response.Page = params.Page
+		response.TotalCount = total
+		response.TotalPages = int((total + int64(limit) - 1) / int64(limit))
+		return response, nil
+	}
+
+	if hasMore {
+		next, err := pagination.EncodeCursor(userCursor(query.Sort, users[len(users)-1]))
+		if err != nil {
+			return nil, common.ServerError
+		}
+		response.NextCursor = next
+	}
+
+	return response, nil
+}
+
+// Search finds users by a free text query that may be partial or misspelled,
+// best matches first.
+func (u *UserUsecase) Search(ctx context.Context, principal *domain.Principal, params *domain.UserSearchParams) (*domain.UserSearchResponse, error) {
+	if principal == nil {
+		return nil, common.Unauthorized
+	}
+	if !principal.Can(domain.PermissionUsersList) {
+		return nil, common.Forbidden
+	}
+
+	query := strings.TrimSpace(params.Query)
+	if query == "" || len(query) > maxSearchLength {
+		return nil, &common.ValidationError{Violations: []common.Violation{{
+			Field:   "q",
+			Rule:    "length",
+			Message: fmt.Sprintf("search query must be between 1 and %d characters", maxSearchLength),
+		}}}
+	}
+
+	hits, err := u.repo.Search(ctx, query, pagination.Limit(params.Limit))
+	if err != nil {
+		return nil, err
+	}
+
+	response := &domain.UserSearchResponse{Users: make([]domain.UserSearchResult, 0, len(hits))}
+	for _, hit := range hits {
+		response.Users = append(response.Users, domain.UserSearchResult{
+			UserResponse: *domain.UserSerializer(hit.User),
+			Score:        hit.Score,
+		})
+	}
+
+	return response, nil
+}
+
+func checkListParams(params *domain.UserListParams) []common.Violation {
+	var violations []common.Violation
+	for _, filter := range params.Filters {
+		if !contains(domain.UserFilterFields, filter.Field) {
+			violations = append(violations, common.Violation{
+				Field:   filter.Field,
+				Rule:    "filterable",
+				Message: fmt.Sprintf("users cannot be filtered by %q", filter.Field),
+			})
+			continue
+		}
+		if !contains(domain.FilterOperators, filter.Operator) {
+			violations = append(violations, common.Violation{
+				Field:   filter.Field,
+				Rule:    "operator",
+				Message: fmt.Sprintf("unknown filter operator %q", filter.Operator),
+			})
+			continue
+		}
+		if len(filter.Values) == 0 || (filter.Operator != domain.FilterIn && len(filter.Values) > 1) {
+			violations = append(violations, common.Violation{
+				Field:   filter.Field,
+				Rule:    "value",
+				Message: fmt.Sprintf("the %s operator takes exactly one value", filter.Operator),
+			})
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, sort := range params.Sort {
+		if !contains(domain.UserSortFields, sort.Field) {
+			violations = append(violations, common.Violation{
+				Field:   sort.Field,
+				Rule:    "sortable",
+				Message: fmt.Sprintf("users cannot be sorted by %q", sort.Field),
+			})
+			continue
+		}
+		if seen[sort.Field] {
+			violations = append(violations, common.Violation{
+				Field:   sort.Field,
+				Rule:    "sortable",
+				Message: fmt.Sprintf("users are already sorted by %q", sort.Field),
+			})
+		}
+		seen[sort.Field] = true
+	}
+	return violations
+}
+
+// userSort appends id to the requested order, unless it is already there, so
+// every row has a distinct position for cursors to point at.
+func userSort(sort []domain.UserSort) []domain.UserSort {
+	for _, s := range sort {
+		if s.Field == "id" {
+			return sort
+		}
+	}
+	return append(append([]domain.UserSort{}, sort...), domain.UserSort{Field: "id"})
+}
+
+func userCursor(sort []domain.UserSort, user *models.User) domain.UserCursor {
+	cursor := domain.UserCursor{ID: user.ID, Values: make([]string, len(sort))}
+	for i, s := range sort {
+		switch s.Field {
+		case "name":
+			cursor.Values[i] = user.Name
+		case "email":
+			cursor.Values[i] = user.Email
+		}
+	}
+	return cursor
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Restore brings back a soft deleted user.
+func (u *UserUsecase) Restore(ctx context.Context, principal *domain.Principal, id int) error {
+	if principal == nil {
+		return common.Unauthorized
+	}
+	if !principal.Can(domain.PermissionUsersRestore) {
+		return common.Forbidden
+	}
+
+	return u.repo.Restore(ctx, id)
+}
+
+// Purge deletes the user permanently, along with everything that references
+// it.
+func (u *UserUsecase) Purge(ctx context.Context, principal *domain.Principal, id int) error {
+	if principal == nil {
+		return common.Unauthorized
+	}
+	if !principal.Can(domain.PermissionUsersPurge) {
+		return common.Forbidden
+	}
+
+	return u.repo.Purge(ctx, id)
+}
+
+// Unlock clears the failed login attempts of the user so they can sign in
+// again before the lockout expires.
+func (u *UserUsecase) Unlock(ctx context.Context, principal *domain.Principal, id int) error {
+	if principal == nil {
+		return common.Unauthorized
+	}
+	if !principal.Can(domain.PermissionUsersUnlock) {
+		return common.Forbidden
+	}
+
+	user, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return u.attemptRepo.Reset(ctx, lockout.EmailKey(user.Email))
+}
+
+func (u *UserUsecase) VerifyEmail(ctx context.Context, verificationToken string) error {
+	if verificationToken == "" {
+		return common.BadRequest
+	}
+
+	stored, err := u.userTokenRepo.GetByHash(ctx, domain.TokenPurposeEmailVerification, token.HashOpaque(verificationToken))
+	if err != nil {
+		return err
+	}
+
+	if stored.UsedAt.Valid || time.Now().After(stored.ExpiresAt) {
+		return common.InvalidToken
+	}
+
+	err = u.userTokenRepo.MarkUsed(ctx, stored.ID)
+	if err != nil {
+		return err
+	}
+
+	return u.repo.MarkEmailVerified(ctx, stored.UserID)
+}
+
+// ResendVerification issues a new verification token. Like password reset it
+// does not reveal whether the email is registered.
+func (u *UserUsecase) ResendVerification(ctx context.Context, email string) error {
+	if strings.TrimSpace(email) == "" {
+		return common.BadRequest
+	}
+
+	email, err := u.emails.Normalize(email)
+	if err != nil {
+		return nil
+	}
+
+	user, err := u.repo.GetByEmail(ctx, email)
+	if err == common.UserNotExist {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt.Valid {
+		return nil
+	}
+
+	return u.sendVerification(ctx, user)
+}
+
+// RequestEmailChange keeps the new address pending and sends a confirmation
+// token to it. The current address stays in use until the token is
+// confirmed.
+func (u *UserUsecase) RequestEmailChange(ctx context.Context, principal *domain.Principal, id int, email string) error {
+	err := u.authorize(principal, id, domain.PermissionUsersUpdate, domain.PermissionUsersUpdateAny)
+	if err != nil {
+		return err
+	}
+
+	email, err = u.emails.Normalize(email)
+	if err != nil {
+		return &common.ValidationError{Violations: []common.Violation{{
+			Field:   "email",
+			Rule:    "format",
+			Message: "email must be a valid address",
+		}}}
+	}
+
+	user, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if emailaddr.Key(email) == emailaddr.Key(user.Email) {
+		return common.BadRequest
+	}
+
+	err = u.repo.SetPendingEmail(ctx, id, email)
+	if err != nil {
+		return err
+	}
+
+	err = u.userTokenRepo.InvalidateAll(ctx, id, domain.TokenPurposeEmailChange)
+	if err != nil {
+		return err
+	}
+
+	changeToken, hash, err := token.NewOpaque()
+	if err != nil {
+		return common.ServerError
+	}
+
+	_, err = u.userTokenRepo.Create(ctx, &models.UserToken{
+		UserID:    id,
+		Purpose:   domain.TokenPurposeEmailChange,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(u.tokens.VerificationTTL()),
+	})
+	if err != nil {
+		return err
+	}
+
+	err = u.notifier.Send(ctx, &domain.Notification{
+		To:      email,
+		Subject: "Confirm your new email address",
+		Body:    fmt.Sprintf("Use this token to confirm your new email address: %s\nIt expires in %s.", changeToken, u.tokens.VerificationTTL()),
+	})
+	if err != nil {
+		return common.ServerError
+	}
+
+	return nil
+}
+
+// ConfirmEmailChange swaps in the pending address the token was sent to and
+// lets the previous address know about it.
+func (u *UserUsecase) ConfirmEmailChange(ctx context.Context, changeToken string) error {
+	if changeToken == "" {
+		return common.BadRequest
+	}
+
+	stored, err := u.userTokenRepo.GetByHash(ctx, domain.TokenPurposeEmailChange, token.HashOpaque(changeToken))
+	if err != nil {
+		return err
+	}
+
+	if stored.UsedAt.Valid || time.Now().After(stored.ExpiresAt) {
+		return common.InvalidToken
+	}
+
+	user, err := u.repo.GetByID(ctx, stored.UserID)
+	if err != nil {
+		return err
+	}
+	if !user.PendingEmail.Valid {
+		return common.InvalidToken
+	}
+
+	err = u.userTokenRepo.MarkUsed(ctx, stored.ID)
+	if err != nil {
+		return err
+	}
+
+	err = u.repo.ChangeEmail(ctx, user.ID, user.PendingEmail.String)
+	if err != nil {
+		return err
 	}
 
-	return serializers, nil
+	err = u.notifier.Send(ctx, &domain.Notification{
+		To:      user.Email,
+		Subject: "Your email address was changed",
+		Body:    fmt.Sprintf("The email address of your account was changed to %s. If you did not do this, contact support.", user.PendingEmail.String),
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error("Email change notice could not be sent", zap.Int("user_id", user.ID), zap.Error(err))
+	}
+
+	return nil
 }
 
-func (u *UserUsecase) validate(user *models.User, create bool) bool {
-	var err error
-	if create {
-		_, err = mail.ParseAddress(user.Email)
+func (u *UserUsecase) sendVerification(ctx context.Context, user *models.User) error {
+	err := u.userTokenRepo.InvalidateAll(ctx, user.ID, domain.TokenPurposeEmailVerification)
+	if err != nil {
+		return err
+	}
+
+	verificationToken, hash, err := token.NewOpaque()
+	if err != nil {
+		return common.ServerError
+	}
+
+	_, err = u.userTokenRepo.Create(ctx, &models.UserToken{
+		UserID:    user.ID,
+		Purpose:   domain.TokenPurposeEmailVerification,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(u.tokens.VerificationTTL()),
+	})
+	if err != nil {
+		return err
 	}
 
-	if strings.TrimSpace(user.Name) == "" || strings.TrimSpace(user.Password) == "" || err != nil {
-		return false
+	err = u.notifier.Send(ctx, &domain.Notification{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body:    fmt.Sprintf("Use this token to verify your email address: %s\nIt expires in %s.", verificationToken, u.tokens.VerificationTTL()),
+	})
+	if err != nil {
+		return common.ServerError
 	}
-	return true
+
+	return nil
 }
 
-func (u *UserUsecase) HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
-	return string(bytes), err
+// authorize lets the principal act on any user with the any permission, or on
+// its own record with the own permission.
+func (u *UserUsecase) authorize(principal *domain.Principal, targetID int, own, any string) error {
+	if principal == nil {
+		return common.Unauthorized
+	}
+	if principal.Can(any) {
+		return nil
+	}
+	if principal.UserID == targetID && principal.Can(own) {
+		return nil
+	}
+	return common.Forbidden
 }
@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/h4yfans/case-study/common/metrics"
+	"github.com/h4yfans/case-study/common/patch"
+	"github.com/h4yfans/case-study/domain"
+	"github.com/h4yfans/case-study/models"
+)
+
+const usecaseName = "user"
+
+// MetricsUserUsecase times every operation of the usecase it wraps.
+type MetricsUserUsecase struct {
+	next domain.UserUsecase
+}
+
+func NewMetricsUserUsecase(next domain.UserUsecase) *MetricsUserUsecase {
+	return &MetricsUserUsecase{
+		next: next,
+	}
+}
+
+func (m *MetricsUserUsecase) observe(operation string, start time.Time, err *error) {
+	metrics.ObserveOperation(usecaseName, operation, start, *err)
+}
+
+func (m *MetricsUserUsecase) Create(ctx context.Context, user *models.User) (response *domain.UserResponse, err error) {
+	defer m.observe("Create", time.Now(), &err)
+	return m.next.Create(ctx, user)
+}
+
+func (m *MetricsUserUsecase) Update(ctx context.Context, principal *domain.Principal, id int, changes patch.Patch, ifMatch string) (response *domain.UserResponse, err error) {
+	defer m.observe("Update", time.Now(), &err)
+	return m.next.Update(ctx, principal, id, changes, ifMatch)
+}
+
+func (m *MetricsUserUsecase) Delete(ctx context.Context, principal *domain.Principal, id int, ifMatch string) (err error) {
+	defer m.observe("Delete", time.Now(), &err)
+	return m.next.Delete(ctx, principal, id, ifMatch)
+}
+
+func (m *MetricsUserUsecase) Restore(ctx context.Context, principal *domain.Principal, id int) (err error) {
+	defer m.observe("Restore", time.Now(), &err)
+	return m.next.Restore(ctx, principal, id)
+}
+
+func (m *MetricsUserUsecase) Purge(ctx context.Context, principal *domain.Principal, id int) (err error) {
+	defer m.observe("Purge", time.Now(), &err)
+	return m.next.Purge(ctx, principal, id)
+}
+
+func (m *MetricsUserUsecase) GetByID(ctx context.Context, principal *domain.Principal, id int) (response *domain.UserResponse, err error) {
+	defer m.observe("GetByID", time.Now(), &err)
+	return m.next.GetByID(ctx, principal, id)
+}
+
+func (m *MetricsUserUsecase) GetAllUser(ctx context.Context, principal *domain.Principal, params *domain.UserListParams) (response *domain.UserListResponse, err error) {
+	defer m.observe("GetAllUser", time.Now(), &err)
+	return m.next.GetAllUser(ctx, principal, params)
+}
+
+func (m *MetricsUserUsecase) Search(ctx context.Context, principal *domain.Principal, params *domain.UserSearchParams) (response *domain.UserSearchResponse, err error) {
+	defer m.observe("Search", time.Now(), &err)
+	return m.next.Search(ctx, principal, params)
+}
+
+func (m *MetricsUserUsecase) Unlock(ctx context.Context, principal *domain.Principal, id int) (err error) {
+	defer m.observe("Unlock", time.Now(), &err)
+	return m.next.Unlock(ctx, principal, id)
+}
+
+func (m *MetricsUserUsecase) VerifyEmail(ctx context.Context, token string) (err error) {
+	defer m.observe("VerifyEmail", time.Now(), &err)
+	return m.next.VerifyEmail(ctx, token)
+}
+
+func (m *MetricsUserUsecase) ResendVerification(ctx context.Context, email string) (err error) {
+	defer m.observe("ResendVerification", time.Now(), &err)
+	return m.next.ResendVerification(ctx, email)
+}
+
+func (m *MetricsUserUsecase) RequestEmailChange(ctx context.Context, principal *domain.Principal, id int, email string) (err error) {
+	defer m.observe("RequestEmailChange", time.Now(), &err)
+	return m.next.RequestEmailChange(ctx, principal, id, email)
+}
+
+func (m *MetricsUserUsecase) ConfirmEmailChange(ctx context.Context, token string) (err error) {
+	defer m.observe("ConfirmEmailChange", time.Now(), &err)
+	return m.next.ConfirmEmailChange(ctx, token)
+}
@@ -0,0 +1,37 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/h4yfans/case-study/common"
+	"github.com/h4yfans/case-study/domain"
+	mocks "github.com/h4yfans/case-study/mocks/domain"
+	"github.com/h4yfans/case-study/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsUserUsecaseForwards(t *testing.T) {
+	mockUCase := new(mocks.UserUsecase)
+	principal := &domain.Principal{UserID: 1}
+	user := &models.User{Name: "Kaan"}
+	response := &domain.UserResponse{ID: 1, Name: "Kaan"}
+
+	mockUCase.On("Create", context.Background(), user).Return(response, nil)
+	mockUCase.On("GetByID", context.Background(), principal, 2).Return(nil, common.UserNotExist)
+	mockUCase.On("Delete", context.Background(), principal, 1, `"abc"`).Return(common.PreconditionFailed)
+
+	u := NewMetricsUserUsecase(mockUCase)
+
+	created, err := u.Create(context.Background(), user)
+	assert.NoError(t, err)
+	assert.Equal(t, response, created)
+
+	found, err := u.GetByID(context.Background(), principal, 2)
+	assert.Equal(t, common.UserNotExist, err)
+	assert.Nil(t, found)
+
+	err = u.Delete(context.Background(), principal, 1, `"abc"`)
+	assert.Equal(t, common.PreconditionFailed, err)
+	mockUCase.AssertExpectations(t)
+}
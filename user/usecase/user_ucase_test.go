@@ -2,11 +2,60 @@ package usecase
 
 import (
 	"context"
+	"net/http"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/h4yfans/case-study/common"
+	"github.com/h4yfans/case-study/common/breached"
+	"github.com/h4yfans/case-study/common/emailaddr"
+	"github.com/h4yfans/case-study/common/etag"
+	"github.com/h4yfans/case-study/common/hasher"
+	"github.com/h4yfans/case-study/common/lockout"
+	"github.com/h4yfans/case-study/common/pagination"
+	"github.com/h4yfans/case-study/common/password"
+	"github.com/h4yfans/case-study/common/patch"
+	"github.com/h4yfans/case-study/common/token"
+	"github.com/h4yfans/case-study/domain"
 	mocks "github.com/h4yfans/case-study/mocks/domain"
 	"github.com/h4yfans/case-study/models"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/volatiletech/null/v8"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	owner = &domain.Principal{
+		UserID:      1,
+		Role:        domain.RoleUser,
+		Permissions: []string{domain.PermissionUsersRead, domain.PermissionUsersUpdate, domain.PermissionUsersDelete},
+	}
+	admin = &domain.Principal{
+		UserID: 2,
+		Role:   domain.RoleAdmin,
+		Permissions: []string{
+			domain.PermissionUsersRead, domain.PermissionUsersUpdate, domain.PermissionUsersDelete, domain.PermissionUsersList,
+			domain.PermissionUsersReadAny, domain.PermissionUsersUpdateAny, domain.PermissionUsersDeleteAny,
+			domain.PermissionUsersUnlock, domain.PermissionUsersRestore, domain.PermissionUsersPurge,
+		},
+	}
+	stranger = &domain.Principal{
+		UserID:      3,
+		Role:        domain.RoleUser,
+		Permissions: owner.Permissions,
+	}
+	tokens = token.NewManager(token.Config{
+		Algorithm:       token.HS256,
+		Secret:          "secret",
+		AccessTokenTTL:  time.Minute,
+		VerificationTTL: time.Hour,
+	})
+	passwordHasher = hasher.New(hasher.Config{Algorithm: hasher.Bcrypt, BcryptCost: bcrypt.MinCost})
+	passwords      = password.NewPolicy(password.Config{MinLength: 8, RequireDigit: true})
+	screener       = breached.NewDisabled()
+	emails         = emailaddr.NewNormalizer(emailaddr.Config{})
 )
 
 func TestCreate(t *testing.T) {
@@ -14,48 +63,369 @@ func TestCreate(t *testing.T) {
 	user := &models.User{
 		Name:     "Kaan",
 		Email:    "kaan@test.com",
-		Password: "123123",
+		Password: "correct-horse-42",
 	}
 
-	userData := user
-	userData.ID = 1
+	userData := &models.User{ID: 1, Name: "Kaan", Email: "kaan@test.com", Role: domain.RoleUser}
+
+	mockTokenRepo := new(mocks.UserTokenRepository)
+	mockNotifier := new(mocks.Notifier)
 
-	mockRepo.On("Create", context.Background(), user).Return(userData, nil)
-	u := NewUserUsecase(mockRepo)
+	mockRepo.On("Create", context.Background(), mock.MatchedBy(func(account *models.User) bool {
+		return account.Name == "Kaan" && account.Email == "kaan@test.com" &&
+			account.Password != "correct-horse-42" && account.Role == domain.RoleUser
+	})).Return(userData, nil)
+	mockTokenRepo.On("InvalidateAll", context.Background(), 1, domain.TokenPurposeEmailVerification).Return(nil)
+	mockTokenRepo.On("Create", context.Background(), mock.AnythingOfType("*models.UserToken")).Return(&models.UserToken{}, nil)
+	mockNotifier.On("Send", context.Background(), mock.AnythingOfType("*domain.Notification")).Return(nil)
+	u := NewUserUsecase(mockRepo, mockTokenRepo, nil, mockNotifier, tokens, passwordHasher, passwords, screener, emails)
 	a, err := u.Create(context.Background(), user)
 	assert.NoError(t, err)
 	assert.NotNil(t, a)
+	assert.False(t, a.EmailVerified)
+	assert.Equal(t, domain.RoleUser, a.Role)
 	mockRepo.AssertExpectations(t)
+	mockTokenRepo.AssertExpectations(t)
+	mockNotifier.AssertExpectations(t)
 }
 
-func TestUpdate(t *testing.T) {
+func TestCreateIgnoresRequestedRole(t *testing.T) {
 	mockRepo := new(mocks.UserRepository)
 	user := &models.User{
 		Name:     "Kaan",
-		Password: "123123",
+		Email:    "kaan@test.com",
+		Password: "correct-horse-42",
+		Role:     domain.RoleAdmin,
 	}
 
-	userData := user
-	userData.ID = 1
+	mockTokenRepo := new(mocks.UserTokenRepository)
+	mockNotifier := new(mocks.Notifier)
 
-	mockRepo.On("Update", context.Background(), user).Return(userData, nil)
-	u := NewUserUsecase(mockRepo)
-	a, err := u.Update(context.Background(), user)
+	mockRepo.On("Create", context.Background(), mock.MatchedBy(func(account *models.User) bool {
+		return account.Role == domain.RoleUser
+	})).Return(&models.User{Name: "Kaan", Role: domain.RoleUser}, nil)
+	mockTokenRepo.On("InvalidateAll", context.Background(), 0, domain.TokenPurposeEmailVerification).Return(nil)
+	mockTokenRepo.On("Create", context.Background(), mock.AnythingOfType("*models.UserToken")).Return(&models.UserToken{}, nil)
+	mockNotifier.On("Send", context.Background(), mock.AnythingOfType("*domain.Notification")).Return(nil)
+	u := NewUserUsecase(mockRepo, mockTokenRepo, nil, mockNotifier, tokens, passwordHasher, passwords, screener, emails)
+	a, err := u.Create(context.Background(), user)
 	assert.NoError(t, err)
-	assert.NotNil(t, a)
+	assert.Equal(t, domain.RoleUser, a.Role)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateIgnoresServerManagedFields(t *testing.T) {
+	mockRepo := new(mocks.UserRepository)
+	user := &models.User{
+		ID:              42,
+		Name:            "Kaan",
+		Email:           "kaan@test.com",
+		Password:        "correct-horse-42",
+		EmailVerifiedAt: null.TimeFrom(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)),
+		PendingEmail:    null.StringFrom("other@test.com"),
+		TotpSecret:      null.StringFrom("secret"),
+		TotpEnabledAt:   null.TimeFrom(time.Now()),
+		DeletedAt:       null.TimeFrom(time.Now()),
+		UpdatedAt:       time.Now(),
+	}
+
+	mockTokenRepo := new(mocks.UserTokenRepository)
+	mockNotifier := new(mocks.Notifier)
+
+	mockRepo.On("Create", context.Background(), mock.MatchedBy(func(account *models.User) bool {
+		return account.ID == 0 &&
+			!account.EmailVerifiedAt.Valid &&
+			!account.PendingEmail.Valid &&
+			!account.TotpSecret.Valid &&
+			!account.TotpEnabledAt.Valid &&
+			!account.DeletedAt.Valid &&
+			account.UpdatedAt.IsZero()
+	})).Return(&models.User{ID: 1, Name: "Kaan", Email: "kaan@test.com", Role: domain.RoleUser}, nil)
+	mockTokenRepo.On("InvalidateAll", context.Background(), 1, domain.TokenPurposeEmailVerification).Return(nil)
+	mockTokenRepo.On("Create", context.Background(), mock.AnythingOfType("*models.UserToken")).Return(&models.UserToken{}, nil)
+	mockNotifier.On("Send", context.Background(), mock.AnythingOfType("*domain.Notification")).Return(nil)
+	u := NewUserUsecase(mockRepo, mockTokenRepo, nil, mockNotifier, tokens, passwordHasher, passwords, screener, emails)
+	a, err := u.Create(context.Background(), user)
+	assert.NoError(t, err)
+	assert.False(t, a.EmailVerified)
+	assert.False(t, a.TwoFactorEnabled)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateNormalizesEmail(t *testing.T) {
+	mockRepo := new(mocks.UserRepository)
+	user := &models.User{
+		Name:     "Kaan",
+		Email:    " Kaan@Test.COM ",
+		Password: "correct-horse-42",
+	}
+
+	mockTokenRepo := new(mocks.UserTokenRepository)
+	mockNotifier := new(mocks.Notifier)
+
+	mockRepo.On("Create", context.Background(), mock.MatchedBy(func(u *models.User) bool {
+		return u.Email == "Kaan@test.com"
+	})).Return(&models.User{ID: 1, Name: "Kaan", Email: "Kaan@test.com"}, nil)
+	mockTokenRepo.On("InvalidateAll", context.Background(), 1, domain.TokenPurposeEmailVerification).Return(nil)
+	mockTokenRepo.On("Create", context.Background(), mock.AnythingOfType("*models.UserToken")).Return(&models.UserToken{}, nil)
+	mockNotifier.On("Send", context.Background(), mock.AnythingOfType("*domain.Notification")).Return(nil)
+	u := NewUserUsecase(mockRepo, mockTokenRepo, nil, mockNotifier, tokens, passwordHasher, passwords, screener, emails)
+	a, err := u.Create(context.Background(), user)
+	assert.NoError(t, err)
+	assert.Equal(t, "Kaan@test.com", a.Email)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateInvalidEmail(t *testing.T) {
+	mockRepo := new(mocks.UserRepository)
+	u := NewUserUsecase(mockRepo, nil, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+
+	for _, email := range []string{"kaan@", strings.Repeat("k", 250) + "@test.com"} {
+		user := &models.User{
+			Name:     "Kaan",
+			Email:    email,
+			Password: "correct-horse-42",
+		}
+
+		_, err := u.Create(context.Background(), user)
+		assert.Equal(t, common.BadRequest, err, email)
+	}
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func mergePatch(t *testing.T, data string) patch.Patch {
+	p, err := patch.NewMergePatch([]byte(data))
+	assert.NoError(t, err)
+	return p
+}
+
+func TestUpdate(t *testing.T) {
+	mockRepo := new(mocks.UserRepository)
+	current := &models.User{ID: 1, Name: "Kaan", Email: "kaan@test.com"}
+
+	mockRepo.On("GetByID", context.Background(), 1).Return(current, nil)
+	mockRepo.On("Update", context.Background(), mock.MatchedBy(func(user *models.User) bool {
+		return user.Name == "Ayse" && user.Password != "correct-horse-42"
+	}), []string{models.UserColumns.Name, models.UserColumns.Password}).Return(&models.User{ID: 1, Name: "Ayse"}, nil)
+	u := NewUserUsecase(mockRepo, nil, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+	a, err := u.Update(context.Background(), owner, 1, mergePatch(t, `{"name":"Ayse","password":"correct-horse-42"}`), "")
+	assert.NoError(t, err)
+	assert.Equal(t, "Ayse", a.Name)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateNameOnly(t *testing.T) {
+	mockRepo := new(mocks.UserRepository)
+	current := &models.User{ID: 1, Name: "Kaan", Email: "kaan@test.com"}
+
+	mockRepo.On("GetByID", context.Background(), 1).Return(current, nil)
+	mockRepo.On("Update", context.Background(), &models.User{ID: 1, Name: "Ayse"}, []string{models.UserColumns.Name}).
+		Return(&models.User{ID: 1, Name: "Ayse"}, nil)
+	u := NewUserUsecase(mockRepo, nil, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+	a, err := u.Update(context.Background(), owner, 1, mergePatch(t, `{"name":"Ayse"}`), "")
+	assert.NoError(t, err)
+	assert.Equal(t, "Ayse", a.Name)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateUnchanged(t *testing.T) {
+	mockRepo := new(mocks.UserRepository)
+	current := &models.User{ID: 1, Name: "Kaan", Email: "kaan@test.com", Role: domain.RoleUser}
+
+	mockRepo.On("GetByID", context.Background(), 1).Return(current, nil)
+	u := NewUserUsecase(mockRepo, nil, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+	a, err := u.Update(context.Background(), owner, 1, mergePatch(t, `{"name":"Kaan","email":"kaan@test.com"}`), "")
+	assert.NoError(t, err)
+	assert.Equal(t, "Kaan", a.Name)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateImmutable(t *testing.T) {
+	mockRepo := new(mocks.UserRepository)
+	current := &models.User{ID: 1, Name: "Kaan", Email: "kaan@test.com", Role: domain.RoleUser}
+
+	mockRepo.On("GetByID", context.Background(), 1).Return(current, nil)
+	u := NewUserUsecase(mockRepo, nil, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+	a, err := u.Update(context.Background(), owner, 1, mergePatch(t, `{"name":null,"role":"admin","nickname":"k"}`), "")
+	assert.Nil(t, a)
+	assert.Equal(t, &common.ValidationError{Violations: []common.Violation{
+		{Field: "nickname", Rule: "immutable", Message: "nickname cannot be changed"},
+		{Field: "role", Rule: "immutable", Message: "role cannot be changed"},
+		{Field: "name", Rule: "required", Message: "name cannot be removed"},
+	}}, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateJSONPatchTestFailed(t *testing.T) {
+	mockRepo := new(mocks.UserRepository)
+	current := &models.User{ID: 1, Name: "Kaan", Email: "kaan@test.com"}
+
+	changes, err := patch.NewJSONPatch([]byte(`[{"op":"test","path":"/name","value":"Ayse"},{"op":"replace","path":"/name","value":"Mehmet"}]`))
+	assert.NoError(t, err)
+
+	mockRepo.On("GetByID", context.Background(), 1).Return(current, nil)
+	u := NewUserUsecase(mockRepo, nil, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+	a, err := u.Update(context.Background(), owner, 1, changes, "")
+	assert.Nil(t, a)
+	assert.Equal(t, common.PreconditionFailed, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdatePrecondition(t *testing.T) {
+	updatedAt := time.Date(2021, 9, 1, 12, 0, 0, 0, time.UTC)
+	current := &models.User{ID: 1, Name: "Kaan", Email: "kaan@test.com", UpdatedAt: updatedAt}
+
+	t.Run("should save over the version matched", func(t *testing.T) {
+		mockRepo := new(mocks.UserRepository)
+
+		mockRepo.On("GetByID", context.Background(), 1).Return(current, nil)
+		mockRepo.On("Update", context.Background(), mock.MatchedBy(func(u *models.User) bool {
+			return u.UpdatedAt.Equal(updatedAt)
+		}), []string{models.UserColumns.Name}).Return(current, nil)
+
+		u := NewUserUsecase(mockRepo, nil, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+		a, err := u.Update(context.Background(), owner, 1, mergePatch(t, `{"name":"Ayse"}`), etag.FromTime(updatedAt))
+		assert.NoError(t, err)
+		assert.NotNil(t, a)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should report a concurrent change as a conflict without If-Match", func(t *testing.T) {
+		mockRepo := new(mocks.UserRepository)
+
+		mockRepo.On("GetByID", context.Background(), 1).Return(current, nil)
+		mockRepo.On("Update", context.Background(), mock.AnythingOfType("*models.User"), []string{models.UserColumns.Name}).Return(nil, common.Conflict)
+
+		u := NewUserUsecase(mockRepo, nil, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+		a, err := u.Update(context.Background(), owner, 1, mergePatch(t, `{"name":"Ayse"}`), "")
+		assert.Equal(t, common.Conflict, err)
+		assert.Nil(t, a)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject a stale version", func(t *testing.T) {
+		mockRepo := new(mocks.UserRepository)
+
+		mockRepo.On("GetByID", context.Background(), 1).Return(current, nil)
+
+		u := NewUserUsecase(mockRepo, nil, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+		a, err := u.Update(context.Background(), owner, 1, mergePatch(t, `{"name":"Ayse"}`), etag.FromTime(updatedAt.Add(-time.Minute)))
+		assert.Equal(t, common.PreconditionFailed, err)
+		assert.Nil(t, a)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestCreatePasswordPolicy(t *testing.T) {
+	mockRepo := new(mocks.UserRepository)
+	user := &models.User{
+		Name:     "Kaan",
+		Email:    "kaan@test.com",
+		Password: "kaan",
+	}
+
+	u := NewUserUsecase(mockRepo, nil, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+	a, err := u.Create(context.Background(), user)
+	assert.Nil(t, a)
+
+	validationErr, ok := err.(*common.ValidationError)
+	assert.True(t, ok)
+	rules := make([]string, 0)
+	for _, violation := range validationErr.Violations {
+		rules = append(rules, violation.Rule)
+	}
+	assert.Equal(t, []string{password.RuleMinLength, password.RuleDigit, password.RulePersonalInfo}, rules)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateBreachedPassword(t *testing.T) {
+	mockRepo := new(mocks.UserRepository)
+	mockScreener := new(mocks.PasswordScreener)
+	user := &models.User{
+		Name:     "Kaan",
+		Email:    "kaan@test.com",
+		Password: "password123",
+	}
+
+	mockScreener.On("Breached", context.Background(), user.Password).Return(true, nil)
+	u := NewUserUsecase(mockRepo, nil, nil, nil, tokens, passwordHasher, passwords, mockScreener, emails)
+	a, err := u.Create(context.Background(), user)
+	assert.Nil(t, a)
+	assert.Equal(t, &common.ValidationError{Violations: []common.Violation{password.Breached()}}, err)
+	mockRepo.AssertExpectations(t)
+	mockScreener.AssertExpectations(t)
+}
+
+func TestUpdatePasswordPolicy(t *testing.T) {
+	mockRepo := new(mocks.UserRepository)
+
+	mockRepo.On("GetByID", context.Background(), 1).Return(&models.User{ID: 1, Name: "Kaan", Email: "kaan@test.com"}, nil)
+	u := NewUserUsecase(mockRepo, nil, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+	a, err := u.Update(context.Background(), owner, 1, mergePatch(t, `{"password":"kaan@test.com1"}`), "")
+	assert.Nil(t, a)
+	assert.Equal(t, http.StatusBadRequest, common.GetStatusCode(err))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateForbidden(t *testing.T) {
+	mockRepo := new(mocks.UserRepository)
+
+	u := NewUserUsecase(mockRepo, nil, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+	a, err := u.Update(context.Background(), stranger, 1, mergePatch(t, `{"name":"Ayse"}`), "")
+	assert.Equal(t, common.Forbidden, err)
+	assert.Nil(t, a)
 	mockRepo.AssertExpectations(t)
 }
 
 func TestDelete(t *testing.T) {
 	mockRepo := new(mocks.UserRepository)
 
-	mockRepo.On("Delete", context.Background(), 1).Return(nil, nil)
-	u := NewUserUsecase(mockRepo)
-	err := u.Delete(context.Background(), 1)
+	user := &models.User{ID: 1, UpdatedAt: time.Date(2021, 9, 1, 12, 0, 0, 0, time.UTC)}
+	mockRepo.On("GetByID", context.Background(), 1).Return(user, nil)
+	mockRepo.On("Delete", context.Background(), user).Return(nil)
+	u := NewUserUsecase(mockRepo, nil, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+	err := u.Delete(context.Background(), admin, 1, "")
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
 
+func TestDeleteConcurrentChange(t *testing.T) {
+	mockRepo := new(mocks.UserRepository)
+	updatedAt := time.Date(2021, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	user := &models.User{ID: 1, UpdatedAt: updatedAt}
+	mockRepo.On("GetByID", context.Background(), 1).Return(user, nil)
+	mockRepo.On("Delete", context.Background(), user).Return(common.Conflict)
+	u := NewUserUsecase(mockRepo, nil, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+
+	err := u.Delete(context.Background(), admin, 1, etag.FromTime(updatedAt))
+	assert.Equal(t, common.PreconditionFailed, err)
+
+	err = u.Delete(context.Background(), admin, 1, "")
+	assert.Equal(t, common.Conflict, err, "without If-Match no precondition failed")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeleteForbidden(t *testing.T) {
+	mockRepo := new(mocks.UserRepository)
+
+	u := NewUserUsecase(mockRepo, nil, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+	err := u.Delete(context.Background(), stranger, 1, "")
+	assert.Equal(t, common.Forbidden, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeletePrecondition(t *testing.T) {
+	mockRepo := new(mocks.UserRepository)
+	updatedAt := time.Date(2021, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	mockRepo.On("GetByID", context.Background(), 1).Return(&models.User{ID: 1, UpdatedAt: updatedAt}, nil)
+	u := NewUserUsecase(mockRepo, nil, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+	err := u.Delete(context.Background(), admin, 1, etag.FromTime(updatedAt.Add(time.Second)))
+	assert.Equal(t, common.PreconditionFailed, err)
+	mockRepo.AssertExpectations(t)
+}
+
 func TestGetByID(t *testing.T) {
 	mockRepo := new(mocks.UserRepository)
 	user := &models.User{
@@ -65,27 +435,455 @@ func TestGetByID(t *testing.T) {
 	}
 
 	mockRepo.On("GetByID", context.Background(), 1).Return(user, nil)
-	u := NewUserUsecase(mockRepo)
-	a, err := u.GetByID(context.Background(), 1)
+	u := NewUserUsecase(mockRepo, nil, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+	a, err := u.GetByID(context.Background(), owner, 1)
 	assert.NoError(t, err)
 	assert.NotNil(t, a)
 	mockRepo.AssertExpectations(t)
 }
 
-func TestGetAllUser(t *testing.T) {
+func TestGetByIDUnauthenticated(t *testing.T) {
 	mockRepo := new(mocks.UserRepository)
+
+	u := NewUserUsecase(mockRepo, nil, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+	a, err := u.GetByID(context.Background(), nil, 1)
+	assert.Equal(t, common.Unauthorized, err)
+	assert.Nil(t, a)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetAllUser(t *testing.T) {
 	userData := models.UserSlice{
-		{
-			ID:       1,
-			Name:     "Kaan",
-			Password: "123123",
-		},
+		{ID: 1, Name: "Kaan"},
+		{ID: 2, Name: "Ayse"},
+		{ID: 3, Name: "Mehmet"},
 	}
+	byID := []domain.UserSort{{Field: "id"}}
 
-	mockRepo.On("GetAllUser", context.Background()).Return(userData, nil)
-	u := NewUserUsecase(mockRepo)
-	a, err := u.GetAllUser(context.Background())
-	assert.NoError(t, err)
-	assert.NotNil(t, a)
+	t.Run("should return the first page with a cursor", func(t *testing.T) {
+		mockRepo := new(mocks.UserRepository)
+		mockRepo.On("GetAllUser", context.Background(), &domain.UserListQuery{Limit: 3, Sort: byID}).Return(userData, nil)
+
+		u := NewUserUsecase(mockRepo, nil, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+		a, err := u.GetAllUser(context.Background(), admin, &domain.UserListParams{Limit: 2})
+		assert.NoError(t, err)
+		assert.Len(t, a.Users, 2)
+		assert.NotEmpty(t, a.NextCursor)
+		mockRepo.AssertExpectations(t)
+
+		mockRepo.On("GetAllUser", context.Background(), &domain.UserListQuery{
+			Limit: 3,
+			After: &domain.UserCursor{ID: 2, Values: []string{""}},
+			Sort:  byID,
+		}).Return(userData[2:], nil)
+		a, err = u.GetAllUser(context.Background(), admin, &domain.UserListParams{Limit: 2, Cursor: a.NextCursor})
+		assert.NoError(t, err)
+		assert.Len(t, a.Users, 1)
+		assert.Equal(t, 3, a.Users[0].ID)
+		assert.Empty(t, a.NextCursor)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should return a page with totals", func(t *testing.T) {
+		mockRepo := new(mocks.UserRepository)
+		mockRepo.On("GetAllUser", context.Background(), &domain.UserListQuery{Limit: 3, Offset: 2, Sort: byID}).Return(userData[2:], nil)
+		mockRepo.On("CountUsers", context.Background(), []domain.UserFilter(nil)).Return(int64(3), nil)
+
+		u := NewUserUsecase(mockRepo, nil, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+		a, err := u.GetAllUser(context.Background(), admin, &domain.UserListParams{Limit: 2, Page: 2})
+		assert.NoError(t, err)
+		assert.Len(t, a.Users, 1)
+		assert.Equal(t, 2, a.Page)
+		assert.Equal(t, int64(3), a.TotalCount)
+		assert.Equal(t, 2, a.TotalPages)
+		assert.Empty(t, a.NextCursor)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject a malformed cursor", func(t *testing.T) {
+		mockRepo := new(mocks.UserRepository)
+
+		u := NewUserUsecase(mockRepo, nil, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+		a, err := u.GetAllUser(context.Background(), admin, &domain.UserListParams{Cursor: "not a cursor"})
+		assert.Equal(t, common.BadRequest, err)
+		assert.Nil(t, a)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should filter and carry the sort in the cursor", func(t *testing.T) {
+		filters := []domain.UserFilter{{Field: "email", Operator: domain.FilterSuffix, Values: []string{"@partner.com"}}}
+		sort := []domain.UserSort{{Field: "name"}, {Field: "id"}}
+
+		mockRepo := new(mocks.UserRepository)
+		mockRepo.On("GetAllUser", context.Background(), &domain.UserListQuery{Limit: 2, Filters: filters, Sort: sort}).Return(userData[:2], nil)
+
+		u := NewUserUsecase(mockRepo, nil, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+		a, err := u.GetAllUser(context.Background(), admin, &domain.UserListParams{
+			Limit:   1,
+			Filters: filters,
+			Sort:    []domain.UserSort{{Field: "name"}},
+		})
+		assert.NoError(t, err)
+		assert.Len(t, a.Users, 1)
+
+		var cursor domain.UserCursor
+		assert.NoError(t, pagination.DecodeCursor(a.NextCursor, &cursor))
+		assert.Equal(t, domain.UserCursor{ID: 1, Values: []string{"Kaan", ""}}, cursor)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject unknown fields and operators", func(t *testing.T) {
+		mockRepo := new(mocks.UserRepository)
+
+		u := NewUserUsecase(mockRepo, nil, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+		a, err := u.GetAllUser(context.Background(), admin, &domain.UserListParams{
+			Filters: []domain.UserFilter{
+				{Field: "password", Operator: domain.FilterEQ, Values: []string{"secret"}},
+				{Field: "name", Operator: "regex", Values: []string{".*"}},
+			},
+			Sort: []domain.UserSort{{Field: "role"}},
+		})
+
+		var validationErr *common.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+		assert.Len(t, validationErr.Violations, 3)
+		assert.Nil(t, a)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject a cursor from another sort", func(t *testing.T) {
+		cursor, err := pagination.EncodeCursor(domain.UserCursor{ID: 1, Values: []string{""}})
+		assert.NoError(t, err)
+
+		mockRepo := new(mocks.UserRepository)
+
+		u := NewUserUsecase(mockRepo, nil, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+		a, err := u.GetAllUser(context.Background(), admin, &domain.UserListParams{
+			Cursor: cursor,
+			Sort:   []domain.UserSort{{Field: "name"}},
+		})
+		assert.Equal(t, common.BadRequest, err)
+		assert.Nil(t, a)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject a cursor with a page", func(t *testing.T) {
+		mockRepo := new(mocks.UserRepository)
+
+		u := NewUserUsecase(mockRepo, nil, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+		a, err := u.GetAllUser(context.Background(), admin, &domain.UserListParams{Cursor: "eyJpZCI6MX0", Page: 1})
+		assert.Equal(t, common.BadRequest, err)
+		assert.Nil(t, a)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestGetAllUserForbidden(t *testing.T) {
+	mockRepo := new(mocks.UserRepository)
+
+	u := NewUserUsecase(mockRepo, nil, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+	a, err := u.GetAllUser(context.Background(), owner, &domain.UserListParams{})
+	assert.Equal(t, common.Forbidden, err)
+	assert.Nil(t, a)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestSearch(t *testing.T) {
+	t.Run("should return hits with their score", func(t *testing.T) {
+		mockRepo := new(mocks.UserRepository)
+		hits := []domain.UserSearchHit{{User: &models.User{ID: 1, Name: "Kaan"}, Score: 0.75}}
+		mockRepo.On("Search", context.Background(), "kan", pagination.DefaultLimit).Return(hits, nil)
+
+		u := NewUserUsecase(mockRepo, nil, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+		a, err := u.Search(context.Background(), admin, &domain.UserSearchParams{Query: " kan "})
+		assert.NoError(t, err)
+		assert.Len(t, a.Users, 1)
+		assert.Equal(t, 1, a.Users[0].ID)
+		assert.Equal(t, 0.75, a.Users[0].Score)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject an empty query", func(t *testing.T) {
+		mockRepo := new(mocks.UserRepository)
+
+		u := NewUserUsecase(mockRepo, nil, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+		a, err := u.Search(context.Background(), admin, &domain.UserSearchParams{Query: "  "})
+
+		var validationErr *common.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+		assert.Nil(t, a)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should require the list permission", func(t *testing.T) {
+		mockRepo := new(mocks.UserRepository)
+
+		u := NewUserUsecase(mockRepo, nil, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+		a, err := u.Search(context.Background(), owner, &domain.UserSearchParams{Query: "kan"})
+		assert.Equal(t, common.Forbidden, err)
+		assert.Nil(t, a)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestVerifyEmail(t *testing.T) {
+	verificationToken, hash, err := token.NewOpaque()
+	assert.NoError(t, err)
+
+	t.Run("should verify email", func(t *testing.T) {
+		mockRepo := new(mocks.UserRepository)
+		mockTokenRepo := new(mocks.UserTokenRepository)
+		stored := &models.UserToken{ID: 5, UserID: 1, Purpose: domain.TokenPurposeEmailVerification, TokenHash: hash, ExpiresAt: time.Now().Add(time.Hour)}
+
+		mockTokenRepo.On("GetByHash", context.Background(), domain.TokenPurposeEmailVerification, hash).Return(stored, nil)
+		mockTokenRepo.On("MarkUsed", context.Background(), stored.ID).Return(nil)
+		mockRepo.On("MarkEmailVerified", context.Background(), 1).Return(nil)
+		u := NewUserUsecase(mockRepo, mockTokenRepo, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+		err := u.VerifyEmail(context.Background(), verificationToken)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		mockTokenRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject expired token", func(t *testing.T) {
+		mockRepo := new(mocks.UserRepository)
+		mockTokenRepo := new(mocks.UserTokenRepository)
+		stored := &models.UserToken{ID: 5, UserID: 1, Purpose: domain.TokenPurposeEmailVerification, TokenHash: hash, ExpiresAt: time.Now().Add(-time.Minute)}
+
+		mockTokenRepo.On("GetByHash", context.Background(), domain.TokenPurposeEmailVerification, hash).Return(stored, nil)
+		u := NewUserUsecase(mockRepo, mockTokenRepo, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+		err := u.VerifyEmail(context.Background(), verificationToken)
+		assert.Equal(t, common.InvalidToken, err)
+		mockRepo.AssertExpectations(t)
+		mockTokenRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject used token", func(t *testing.T) {
+		mockRepo := new(mocks.UserRepository)
+		mockTokenRepo := new(mocks.UserTokenRepository)
+		stored := &models.UserToken{ID: 5, UserID: 1, Purpose: domain.TokenPurposeEmailVerification, TokenHash: hash, ExpiresAt: time.Now().Add(time.Hour), UsedAt: null.TimeFrom(time.Now())}
+
+		mockTokenRepo.On("GetByHash", context.Background(), domain.TokenPurposeEmailVerification, hash).Return(stored, nil)
+		u := NewUserUsecase(mockRepo, mockTokenRepo, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+		err := u.VerifyEmail(context.Background(), verificationToken)
+		assert.Equal(t, common.InvalidToken, err)
+		mockRepo.AssertExpectations(t)
+		mockTokenRepo.AssertExpectations(t)
+	})
+}
+
+func TestRequestEmailChange(t *testing.T) {
+	current := &models.User{ID: 1, Name: "Kaan", Email: "kaan@test.com"}
+
+	t.Run("should send a token to the new address", func(t *testing.T) {
+		mockRepo := new(mocks.UserRepository)
+		mockTokenRepo := new(mocks.UserTokenRepository)
+		mockNotifier := new(mocks.Notifier)
+
+		mockRepo.On("GetByID", context.Background(), 1).Return(current, nil)
+		mockRepo.On("SetPendingEmail", context.Background(), 1, "kaan@partner.com").Return(nil)
+		mockTokenRepo.On("InvalidateAll", context.Background(), 1, domain.TokenPurposeEmailChange).Return(nil)
+		mockTokenRepo.On("Create", context.Background(), mock.MatchedBy(func(token *models.UserToken) bool {
+			return token.UserID == 1 && token.Purpose == domain.TokenPurposeEmailChange
+		})).Return(&models.UserToken{}, nil)
+		mockNotifier.On("Send", context.Background(), mock.MatchedBy(func(n *domain.Notification) bool {
+			return n.To == "kaan@partner.com"
+		})).Return(nil)
+
+		u := NewUserUsecase(mockRepo, mockTokenRepo, nil, mockNotifier, tokens, passwordHasher, passwords, screener, emails)
+		err := u.RequestEmailChange(context.Background(), owner, 1, " kaan@partner.com ")
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		mockTokenRepo.AssertExpectations(t)
+		mockNotifier.AssertExpectations(t)
+	})
+
+	t.Run("should reject a taken address", func(t *testing.T) {
+		mockRepo := new(mocks.UserRepository)
+
+		mockRepo.On("GetByID", context.Background(), 1).Return(current, nil)
+		mockRepo.On("SetPendingEmail", context.Background(), 1, "ayse@test.com").Return(common.UserAlreadyExist)
+
+		u := NewUserUsecase(mockRepo, nil, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+		err := u.RequestEmailChange(context.Background(), owner, 1, "ayse@test.com")
+		assert.Equal(t, common.UserAlreadyExist, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject an invalid address", func(t *testing.T) {
+		mockRepo := new(mocks.UserRepository)
+
+		u := NewUserUsecase(mockRepo, nil, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+		err := u.RequestEmailChange(context.Background(), owner, 1, "not an address")
+
+		var validationErr *common.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should not change another user's address", func(t *testing.T) {
+		mockRepo := new(mocks.UserRepository)
+
+		u := NewUserUsecase(mockRepo, nil, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+		err := u.RequestEmailChange(context.Background(), stranger, 1, "kaan@partner.com")
+		assert.Equal(t, common.Forbidden, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestConfirmEmailChange(t *testing.T) {
+	changeToken, hash, err := token.NewOpaque()
+	assert.NoError(t, err)
+	current := &models.User{ID: 1, Email: "kaan@test.com", PendingEmail: null.StringFrom("kaan@partner.com")}
+
+	t.Run("should swap the address and notify the old one", func(t *testing.T) {
+		mockRepo := new(mocks.UserRepository)
+		mockTokenRepo := new(mocks.UserTokenRepository)
+		mockNotifier := new(mocks.Notifier)
+		stored := &models.UserToken{ID: 5, UserID: 1, Purpose: domain.TokenPurposeEmailChange, TokenHash: hash, ExpiresAt: time.Now().Add(time.Hour)}
+
+		mockTokenRepo.On("GetByHash", context.Background(), domain.TokenPurposeEmailChange, hash).Return(stored, nil)
+		mockTokenRepo.On("MarkUsed", context.Background(), stored.ID).Return(nil)
+		mockRepo.On("GetByID", context.Background(), 1).Return(current, nil)
+		mockRepo.On("ChangeEmail", context.Background(), 1, "kaan@partner.com").Return(nil)
+		mockNotifier.On("Send", context.Background(), mock.MatchedBy(func(n *domain.Notification) bool {
+			return n.To == "kaan@test.com"
+		})).Return(nil)
+
+		u := NewUserUsecase(mockRepo, mockTokenRepo, nil, mockNotifier, tokens, passwordHasher, passwords, screener, emails)
+		err := u.ConfirmEmailChange(context.Background(), changeToken)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		mockTokenRepo.AssertExpectations(t)
+		mockNotifier.AssertExpectations(t)
+	})
+
+	t.Run("should report an address claimed meanwhile", func(t *testing.T) {
+		mockRepo := new(mocks.UserRepository)
+		mockTokenRepo := new(mocks.UserTokenRepository)
+		stored := &models.UserToken{ID: 5, UserID: 1, Purpose: domain.TokenPurposeEmailChange, TokenHash: hash, ExpiresAt: time.Now().Add(time.Hour)}
+
+		mockTokenRepo.On("GetByHash", context.Background(), domain.TokenPurposeEmailChange, hash).Return(stored, nil)
+		mockTokenRepo.On("MarkUsed", context.Background(), stored.ID).Return(nil)
+		mockRepo.On("GetByID", context.Background(), 1).Return(current, nil)
+		mockRepo.On("ChangeEmail", context.Background(), 1, "kaan@partner.com").Return(common.UserAlreadyExist)
+
+		u := NewUserUsecase(mockRepo, mockTokenRepo, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+		err := u.ConfirmEmailChange(context.Background(), changeToken)
+		assert.Equal(t, common.UserAlreadyExist, err)
+		mockRepo.AssertExpectations(t)
+		mockTokenRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject an expired token", func(t *testing.T) {
+		mockRepo := new(mocks.UserRepository)
+		mockTokenRepo := new(mocks.UserTokenRepository)
+		stored := &models.UserToken{ID: 5, UserID: 1, Purpose: domain.TokenPurposeEmailChange, TokenHash: hash, ExpiresAt: time.Now().Add(-time.Minute)}
+
+		mockTokenRepo.On("GetByHash", context.Background(), domain.TokenPurposeEmailChange, hash).Return(stored, nil)
+
+		u := NewUserUsecase(mockRepo, mockTokenRepo, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+		err := u.ConfirmEmailChange(context.Background(), changeToken)
+		assert.Equal(t, common.InvalidToken, err)
+		mockRepo.AssertExpectations(t)
+		mockTokenRepo.AssertExpectations(t)
+	})
+}
+
+func TestResendVerification(t *testing.T) {
+	t.Run("should send new token", func(t *testing.T) {
+		mockRepo := new(mocks.UserRepository)
+		mockTokenRepo := new(mocks.UserTokenRepository)
+		mockNotifier := new(mocks.Notifier)
+		user := &models.User{ID: 1, Email: "kaan@test.com"}
+
+		mockRepo.On("GetByEmail", context.Background(), user.Email).Return(user, nil)
+		mockTokenRepo.On("InvalidateAll", context.Background(), 1, domain.TokenPurposeEmailVerification).Return(nil)
+		mockTokenRepo.On("Create", context.Background(), mock.AnythingOfType("*models.UserToken")).Return(&models.UserToken{}, nil)
+		mockNotifier.On("Send", context.Background(), mock.AnythingOfType("*domain.Notification")).Return(nil)
+		u := NewUserUsecase(mockRepo, mockTokenRepo, nil, mockNotifier, tokens, passwordHasher, passwords, screener, emails)
+		err := u.ResendVerification(context.Background(), user.Email)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		mockTokenRepo.AssertExpectations(t)
+		mockNotifier.AssertExpectations(t)
+	})
+
+	t.Run("should ignore verified email", func(t *testing.T) {
+		mockRepo := new(mocks.UserRepository)
+		user := &models.User{ID: 1, Email: "kaan@test.com", EmailVerifiedAt: null.TimeFrom(time.Now())}
+
+		mockRepo.On("GetByEmail", context.Background(), user.Email).Return(user, nil)
+		u := NewUserUsecase(mockRepo, nil, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+		err := u.ResendVerification(context.Background(), user.Email)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestRestore(t *testing.T) {
+	t.Run("should restore the user", func(t *testing.T) {
+		mockRepo := new(mocks.UserRepository)
+		mockRepo.On("Restore", context.Background(), 1).Return(nil)
+
+		u := NewUserUsecase(mockRepo, nil, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+		err := u.Restore(context.Background(), admin, 1)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should require restore permission", func(t *testing.T) {
+		mockRepo := new(mocks.UserRepository)
+
+		u := NewUserUsecase(mockRepo, nil, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+		err := u.Restore(context.Background(), owner, 1)
+		assert.Equal(t, common.Forbidden, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestPurge(t *testing.T) {
+	t.Run("should purge the user", func(t *testing.T) {
+		mockRepo := new(mocks.UserRepository)
+		mockRepo.On("Purge", context.Background(), 1).Return(nil)
+
+		u := NewUserUsecase(mockRepo, nil, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+		err := u.Purge(context.Background(), admin, 1)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should require purge permission", func(t *testing.T) {
+		mockRepo := new(mocks.UserRepository)
+
+		u := NewUserUsecase(mockRepo, nil, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+		err := u.Purge(context.Background(), owner, 1)
+		assert.Equal(t, common.Forbidden, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestUnlock(t *testing.T) {
+	t.Run("should reset attempts", func(t *testing.T) {
+		mockRepo := new(mocks.UserRepository)
+		mockAttemptRepo := new(mocks.LoginAttemptRepository)
+		user := &models.User{ID: 1, Email: "kaan@test.com"}
+
+		mockRepo.On("GetByID", context.Background(), 1).Return(user, nil)
+		mockAttemptRepo.On("Reset", context.Background(), lockout.EmailKey(user.Email)).Return(nil)
+		u := NewUserUsecase(mockRepo, nil, mockAttemptRepo, nil, tokens, passwordHasher, passwords, screener, emails)
+		err := u.Unlock(context.Background(), admin, 1)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		mockAttemptRepo.AssertExpectations(t)
+	})
+
+	t.Run("should require unlock permission", func(t *testing.T) {
+		mockRepo := new(mocks.UserRepository)
+
+		u := NewUserUsecase(mockRepo, nil, nil, nil, tokens, passwordHasher, passwords, screener, emails)
+		err := u.Unlock(context.Background(), owner, 1)
+		assert.Equal(t, common.Forbidden, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/h4yfans/case-study/common/patch"
+	"github.com/h4yfans/case-study/common/tracing"
+	"github.com/h4yfans/case-study/domain"
+	"github.com/h4yfans/case-study/models"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingUserUsecase runs every operation of the usecase it wraps in a child
+// span of the request's span.
+type TracingUserUsecase struct {
+	next domain.UserUsecase
+}
+
+func NewTracingUserUsecase(next domain.UserUsecase) *TracingUserUsecase {
+	return &TracingUserUsecase{
+		next: next,
+	}
+}
+
+func (t *TracingUserUsecase) start(ctx context.Context, operation string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, "UserUsecase."+operation)
+}
+
+func (t *TracingUserUsecase) end(span trace.Span, err *error) {
+	tracing.End(span, *err)
+}
+
+func (t *TracingUserUsecase) Create(ctx context.Context, user *models.User) (response *domain.UserResponse, err error) {
+	ctx, span := t.start(ctx, "Create")
+	defer t.end(span, &err)
+	return t.next.Create(ctx, user)
+}
+
+func (t *TracingUserUsecase) Update(ctx context.Context, principal *domain.Principal, id int, changes patch.Patch, ifMatch string) (response *domain.UserResponse, err error) {
+	ctx, span := t.start(ctx, "Update")
+	defer t.end(span, &err)
+	return t.next.Update(ctx, principal, id, changes, ifMatch)
+}
+
+func (t *TracingUserUsecase) Delete(ctx context.Context, principal *domain.Principal, id int, ifMatch string) (err error) {
+	ctx, span := t.start(ctx, "Delete")
+	defer t.end(span, &err)
+	return t.next.Delete(ctx, principal, id, ifMatch)
+}
+
+func (t *TracingUserUsecase) Restore(ctx context.Context, principal *domain.Principal, id int) (err error) {
+	ctx, span := t.start(ctx, "Restore")
+	defer t.end(span, &err)
+	return t.next.Restore(ctx, principal, id)
+}
+
+func (t *TracingUserUsecase) Purge(ctx context.Context, principal *domain.Principal, id int) (err error) {
+	ctx, span := t.start(ctx, "Purge")
+	defer t.end(span, &err)
+	return t.next.Purge(ctx, principal, id)
+}
+
+func (t *TracingUserUsecase) GetByID(ctx context.Context, principal *domain.Principal, id int) (response *domain.UserResponse, err error) {
+	ctx, span := t.start(ctx, "GetByID")
+	defer t.end(span, &err)
+	return t.next.GetByID(ctx, principal, id)
+}
+
+func (t *TracingUserUsecase) GetAllUser(ctx context.Context, principal *domain.Principal, params *domain.UserListParams) (response *domain.UserListResponse, err error) {
+	ctx, span := t.start(ctx, "GetAllUser")
+	defer t.end(span, &err)
+	return t.next.GetAllUser(ctx, principal, params)
+}
+
+func (t *TracingUserUsecase) Search(ctx context.Context, principal *domain.Principal, params *domain.UserSearchParams) (response *domain.UserSearchResponse, err error) {
+	ctx, span := t.start(ctx, "Search")
+	defer t.end(span, &err)
+	return t.next.Search(ctx, principal, params)
+}
+
+func (t *TracingUserUsecase) Unlock(ctx context.Context, principal *domain.Principal, id int) (err error) {
+	ctx, span := t.start(ctx, "Unlock")
+	defer t.end(span, &err)
+	return t.next.Unlock(ctx, principal, id)
+}
+
+func (t *TracingUserUsecase) VerifyEmail(ctx context.Context, token string) (err error) {
+	ctx, span := t.start(ctx, "VerifyEmail")
+	defer t.end(span, &err)
+	return t.next.VerifyEmail(ctx, token)
+}
+
+func (t *TracingUserUsecase) ResendVerification(ctx context.Context, email string) (err error) {
+	ctx, span := t.start(ctx, "ResendVerification")
+	defer t.end(span, &err)
+	return t.next.ResendVerification(ctx, email)
+}
+
+func (t *TracingUserUsecase) RequestEmailChange(ctx context.Context, principal *domain.Principal, id int, email string) (err error) {
+	ctx, span := t.start(ctx, "RequestEmailChange")
+	defer t.end(span, &err)
+	return t.next.RequestEmailChange(ctx, principal, id, email)
+}
+
+func (t *TracingUserUsecase) ConfirmEmailChange(ctx context.Context, token string) (err error) {
+	ctx, span := t.start(ctx, "ConfirmEmailChange")
+	defer t.end(span, &err)
+	return t.next.ConfirmEmailChange(ctx, token)
+}
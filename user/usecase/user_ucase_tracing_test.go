@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/h4yfans/case-study/common"
+	"github.com/h4yfans/case-study/domain"
+	mocks "github.com/h4yfans/case-study/mocks/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracingUserUsecase(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(previous)
+
+	principal := &domain.Principal{UserID: 1}
+	mockUCase := new(mocks.UserUsecase)
+	// The wrapped usecase runs inside the span, so its queries nest under it.
+	inSpan := mock.MatchedBy(func(ctx context.Context) bool {
+		return trace.SpanContextFromContext(ctx).IsValid()
+	})
+	mockUCase.On("Unlock", inSpan, principal, 2).Return(common.Forbidden)
+
+	u := NewTracingUserUsecase(mockUCase)
+	err := u.Unlock(context.Background(), principal, 2)
+	assert.Equal(t, common.Forbidden, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "UserUsecase.Unlock", spans[0].Name())
+	mockUCase.AssertExpectations(t)
+}